@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/zengjie/try/core"
+)
+
+// RunCache implements `try cache list|update|gc`
+func RunCache(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: try cache list|update [url...]|gc [--max-age <days>]")
+	}
+
+	switch args[0] {
+	case "list":
+		return listMirrors()
+	case "update":
+		return updateMirrors(args[1:])
+	case "gc":
+		maxAge := core.GetConfig().Int("cache.max-age", 90)
+		for i := 1; i < len(args); i++ {
+			if args[i] == "--max-age" && i+1 < len(args) {
+				days, err := strconv.Atoi(args[i+1])
+				if err != nil || days < 0 {
+					return fmt.Errorf("invalid --max-age %q", args[i+1])
+				}
+				maxAge = days
+				i++
+			} else {
+				return fmt.Errorf("unknown gc option %s", args[i])
+			}
+		}
+		return gcMirrors(time.Duration(maxAge) * 24 * time.Hour)
+	}
+
+	return fmt.Errorf("unknown cache command %s", args[0])
+}
+
+func listMirrors() error {
+	mirrors, err := core.ListMirrors()
+	if err != nil {
+		return err
+	}
+
+	if len(mirrors) == 0 {
+		fmt.Fprintf(os.Stderr, "No mirrors in %s\n", core.MirrorRoot())
+		return nil
+	}
+
+	for _, mirror := range mirrors {
+		fmt.Printf("%-60s %10s  used %s\n", mirror.URL, core.FormatSize(mirror.Size), core.GetRelativeAge(mirror.LastUsed))
+	}
+	return nil
+}
+
+func updateMirrors(urls []string) error {
+	if len(urls) > 0 {
+		for _, url := range urls {
+			gitURL, err := core.ParseGitURL(url)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Updating %s\n", gitURL.URL)
+			if _, err := core.EnsureMirror(context.Background(), gitURL, os.Stderr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	mirrors, err := core.ListMirrors()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, mirror := range mirrors {
+		fmt.Fprintf(os.Stderr, "Updating %s\n", mirror.URL)
+		if err := core.UpdateMirror(mirror, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d mirrors failed to update", failed, len(mirrors))
+	}
+	return nil
+}
+
+func gcMirrors(maxAge time.Duration) error {
+	removed, err := core.GCMirrors(maxAge, os.Stderr)
+	for _, mirror := range removed {
+		fmt.Fprintf(os.Stderr, "Removed %s (%s, last used %s)\n", mirror.URL, core.FormatSize(mirror.Size), core.GetRelativeAge(mirror.LastUsed))
+	}
+	return err
+}
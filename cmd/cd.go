@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zengjie/try/core"
+)
+
+// isTerminal reports whether try talks to a person, i.e. whether a
+// subshell would be usable. It is a variable so tests can pretend.
+var isTerminal = func() bool {
+	for _, file := range []*os.File{os.Stdin, os.Stdout} {
+		stat, err := file.Stat()
+		if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// changeDirectory takes the shell to path once try exits. The wrapper from
+// `try init` does the cd itself; without it cd-mode decides between a
+// subshell in the try, printing the path and writing ~/.try_cd. Commands
+// that have always printed the path pass announce to keep doing so.
+func changeDirectory(path string, announce bool) error {
+	if core.HasWrapper() {
+		if err := core.WriteCdPath(path); err != nil {
+			return err
+		}
+		if announce {
+			fmt.Println(path)
+		}
+		return nil
+	}
+
+	mode, err := core.CdMode()
+	if err != nil {
+		return err
+	}
+
+	// Scripts and pipes can't use a subshell, they get the path instead
+	if mode == core.CdModeSubshell && !isTerminal() {
+		mode = core.CdModePrint
+	}
+
+	switch mode {
+	case core.CdModeFile:
+		if err := core.WriteCdPath(path); err != nil {
+			return err
+		}
+		if announce {
+			fmt.Println(path)
+		}
+	case core.CdModePrint:
+		fmt.Println(path)
+	case core.CdModeSubshell:
+		fmt.Fprintf(os.Stderr, "try: starting a shell in %s, exit to return (eval \"$(try init)\" in your shell config lets try cd instead)\n", path)
+		return core.StartSubshell(path)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withoutWrapper clears the variables the shell wrapper sets and uses the
+// given config
+func withoutWrapper(t *testing.T, config string) {
+	t.Helper()
+	for _, env := range []string{"TRY_WRAPPER", "TRY_CD_FILE", "TRY_BINARY"} {
+		t.Setenv(env, "")
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte(config), 0644)
+	t.Setenv("TRY_CONFIG", path)
+	t.Setenv("HOME", t.TempDir())
+}
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestChangeDirectorySubshell(t *testing.T) {
+	withoutWrapper(t, "")
+	origIsTerminal := isTerminal
+	isTerminal = func() bool { return true }
+	t.Cleanup(func() { isTerminal = origIsTerminal })
+
+	// A fake shell that records where it ran
+	dir := t.TempDir()
+	log := filepath.Join(dir, "shell.log")
+	shell := filepath.Join(dir, "fakesh")
+	os.WriteFile(shell, []byte("#!/bin/sh\necho \"$PWD $TRY_SUBSHELL\" > "+log+"\n"), 0755)
+	t.Setenv("SHELL", shell)
+
+	try := t.TempDir()
+	if err := changeDirectory(try, false); err != nil {
+		t.Fatalf("changeDirectory failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(log)
+	if want := try + " " + try; strings.TrimSpace(string(got)) != want {
+		t.Errorf("subshell ran as %q, want %q", strings.TrimSpace(string(got)), want)
+	}
+}
+
+func TestChangeDirectoryWithoutTerminalPrints(t *testing.T) {
+	withoutWrapper(t, "")
+	origIsTerminal := isTerminal
+	isTerminal = func() bool { return false }
+	t.Cleanup(func() { isTerminal = origIsTerminal })
+	t.Setenv("SHELL", "/nonexistent/shell")
+
+	out := captureStdout(t, func() {
+		if err := changeDirectory("/tries/2025-08-30-redis", false); err != nil {
+			t.Errorf("changeDirectory failed: %v", err)
+		}
+	})
+	if out != "/tries/2025-08-30-redis\n" {
+		t.Errorf("stdout = %q, want the path", out)
+	}
+}
+
+func TestChangeDirectoryFileMode(t *testing.T) {
+	withoutWrapper(t, "cd-mode: file\n")
+
+	out := captureStdout(t, func() {
+		if err := changeDirectory("/tries/2025-08-30-redis", true); err != nil {
+			t.Errorf("changeDirectory failed: %v", err)
+		}
+	})
+	if out != "/tries/2025-08-30-redis\n" {
+		t.Errorf("stdout = %q, want the announced path", out)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(os.Getenv("HOME"), ".try_cd"))
+	if string(got) != "/tries/2025-08-30-redis" {
+		t.Errorf("~/.try_cd = %q, want the path", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"github.com/zengjie/try/core"
+	"github.com/zengjie/try/shell"
+)
+
+// ArgKind says what the positional arguments of a command complete to
+type ArgKind int
+
+const (
+	ArgNone  ArgKind = iota // Nothing worth completing, e.g. a new name
+	ArgTry                  // Try names, ranked by the scorer
+	ArgDir                  // Directories on disk
+	ArgWords                // The fixed words in Command.Words
+)
+
+// Flag is an option accepted by a command
+type Flag struct {
+	Name   string
+	Arg    bool            // The flag takes a value
+	Values func() []string // Completions for the value, if there are any
+}
+
+// Command describes a subcommand for shell completion
+type Command struct {
+	Name   string
+	Args   ArgKind
+	Words  []string
+	Flags  []Flag
+	Hidden bool
+}
+
+// Commands lists the subcommands of try
+var Commands = []Command{
+	{Name: "new", Flags: []Flag{
+		{Name: "--template", Arg: true, Values: templateNames},
+		{Name: "-t", Arg: true, Values: templateNames},
+		{Name: "--lang", Arg: true, Values: core.ScaffoldLanguages},
+		{Name: "--commit"},
+	}},
+	{Name: "clone", Flags: []Flag{
+		{Name: "--depth", Arg: true},
+		{Name: "--full"},
+		{Name: "--filter", Arg: true, Values: words("blob:none", "tree:0")},
+		{Name: "--branch", Arg: true},
+		{Name: "-b", Arg: true},
+		{Name: "--recurse-submodules"},
+		{Name: "--no-recurse-submodules"},
+		{Name: "--sparse", Arg: true},
+		{Name: "--no-sparse"},
+		{Name: "--owner"},
+		{Name: "--no-owner"},
+		{Name: "--cache"},
+		{Name: "--no-cache"},
+	}},
+	{Name: "worktree", Args: ArgTry},
+	{Name: "detach-worktree", Args: ArgTry},
+	{Name: "open", Args: ArgTry, Flags: []Flag{
+		{Name: "--tmux"},
+		{Name: "--zellij"},
+		{Name: "--with", Arg: true, Values: core.OpenerNames},
+		{Name: "--cd"},
+	}},
+	{Name: "exec", Args: ArgTry, Flags: []Flag{
+		{Name: "--all"},
+		{Name: "--filter", Arg: true, Values: words("is:git", "is:worktree", "is:promoted", "is:dirty")},
+		{Name: "--jobs", Arg: true},
+		{Name: "-j", Arg: true},
+	}},
+	{Name: "fork", Args: ArgTry, Flags: []Flag{
+		{Name: "--skip-artifacts"},
+		{Name: "--worktree"},
+	}},
+	{Name: "rename", Args: ArgTry},
+	{Name: "promote", Args: ArgTry, Flags: []Flag{
+		{Name: "--keep-date"},
+		{Name: "--git-init"},
+	}},
+	{Name: "templates"},
+	{Name: "cache", Args: ArgWords, Words: []string{"list", "update", "gc"}, Flags: []Flag{
+		{Name: "--max-age", Arg: true},
+	}},
+	{Name: "hooks", Args: ArgWords, Words: []string{"allow", "deny"}},
+	{Name: "init", Args: ArgDir, Flags: []Flag{
+		{Name: "--shell", Arg: true, Values: words(shell.Shells...)},
+		{Name: "--cmd", Arg: true},
+	}},
+	{Name: "completion", Args: ArgWords, Words: shell.CompletionShells, Flags: []Flag{
+		{Name: "--cmd", Arg: true},
+	}},
+	{Name: "help"},
+	{Name: "__complete", Hidden: true},
+}
+
+// FindCommand returns the command called name, or nil
+func FindCommand(name string) *Command {
+	for i := range Commands {
+		if Commands[i].Name == name {
+			return &Commands[i]
+		}
+	}
+	return nil
+}
+
+// FindFlag returns the command's flag called name, or nil
+func (c *Command) FindFlag(name string) *Flag {
+	for i := range c.Flags {
+		if c.Flags[i].Name == name {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+func words(values ...string) func() []string {
+	return func() []string {
+		return values
+	}
+}
+
+func templateNames() []string {
+	templates, _ := core.ListTemplates()
+
+	var names []string
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+	return names
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zengjie/try/core"
+	"github.com/zengjie/try/shell"
+)
+
+// RunComplete implements the hidden `try __complete <words...>` used by the
+// completion scripts. The words are the command line after `try`, the last
+// one being the word under the cursor (possibly empty). Candidates are
+// printed one per line.
+func RunComplete(args []string) error {
+	for _, candidate := range Complete(args) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+// Complete returns the completion candidates for a command line
+func Complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	// `try <query>` completes subcommands and try names alike
+	if len(words) == 1 {
+		var candidates []string
+		for _, command := range Commands {
+			if !command.Hidden && strings.HasPrefix(command.Name, current) {
+				candidates = append(candidates, command.Name)
+			}
+		}
+		if strings.HasPrefix("--help", current) && current != "" {
+			candidates = append(candidates, "--help")
+		}
+		return append(candidates, completeTries(current)...)
+	}
+
+	command := FindCommand(words[0])
+	if command == nil {
+		return nil
+	}
+
+	// Everything after -- belongs to another program, e.g. in `try exec`
+	for _, word := range words[1 : len(words)-1] {
+		if word == "--" {
+			return nil
+		}
+	}
+
+	// The value of a flag, e.g. `try new --lang <tab>`
+	if flag := command.FindFlag(words[len(words)-2]); flag != nil && flag.Arg {
+		if flag.Values == nil {
+			return nil
+		}
+		return withPrefix(flag.Values(), current)
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var names []string
+		for _, flag := range command.Flags {
+			names = append(names, flag.Name)
+		}
+		return withPrefix(names, current)
+	}
+
+	switch command.Args {
+	case ArgTry:
+		return completeTries(current)
+	case ArgDir:
+		return completeDirs(current)
+	case ArgWords:
+		return withPrefix(command.Words, current)
+	}
+	return nil
+}
+
+func withPrefix(values []string, prefix string) []string {
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			matches = append(matches, value)
+		}
+	}
+	return matches
+}
+
+// completeTries ranks the tries against query with the same scorer as the
+// selector, so the shell offers what the selector would show first
+func completeTries(query string) []string {
+	directories, err := core.ScanDirectories()
+	if err != nil {
+		return nil
+	}
+
+	scored := core.FilterAndScoreDirectories(directories, query)
+	core.SortDirectoriesByScore(scored)
+
+	var names []string
+	for _, dir := range scored {
+		names = append(names, dir.Name)
+	}
+	return names
+}
+
+func completeDirs(prefix string) []string {
+	matches, _ := filepath.Glob(core.ExpandHome(prefix) + "*")
+
+	var dirs []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			if strings.HasPrefix(prefix, "~") {
+				home, _ := os.UserHomeDir()
+				match = "~" + strings.TrimPrefix(match, home)
+			}
+			dirs = append(dirs, match+string(filepath.Separator))
+		}
+	}
+	return dirs
+}
+
+// RunCompletion implements `try completion <shell> [--cmd <name>]`
+func RunCompletion(args []string) error {
+	var shellName, command string
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--cmd" && i+1 < len(args):
+			i++
+			command = args[i]
+		case strings.HasPrefix(args[i], "--cmd="):
+			command = strings.TrimPrefix(args[i], "--cmd=")
+		case strings.HasPrefix(args[i], "-"):
+			return fmt.Errorf("unknown option %s", args[i])
+		default:
+			shellName = args[i]
+		}
+	}
+
+	if shellName == "" {
+		return fmt.Errorf("usage: try completion %s [--cmd <name>]", strings.Join(shell.CompletionShells, "|"))
+	}
+
+	script, err := shell.GenerateCompletion(shell.Options{Shell: shellName, Command: command})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(script)
+	return nil
+}
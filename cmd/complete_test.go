@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComplete(t *testing.T) {
+	root := t.TempDir()
+	tries := filepath.Join(root, "tries")
+	t.Setenv("TRY_PATH", tries)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	for _, name := range []string{"2025-01-10-redis-cluster", "2025-08-30-redis", "2025-08-30-react-app"} {
+		path := filepath.Join(tries, name)
+		os.MkdirAll(path, 0755)
+		if name == "2025-01-10-redis-cluster" {
+			os.Chtimes(path, old, old)
+		}
+	}
+	os.MkdirAll(filepath.Join(root, "projects", "alpha"), 0755)
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		// Subcommands and try names complete side by side
+		{[]string{"cl"}, []string{"clone", "2025-01-10-redis-cluster"}},
+		{[]string{"redis"}, []string{"2025-08-30-redis", "2025-01-10-redis-cluster"}},
+		{[]string{"promote", "react"}, []string{"2025-08-30-react-app"}},
+		{[]string{"promote", "--"}, []string{"--keep-date", "--git-init"}},
+		{[]string{"new", "--lang", "r"}, []string{"rust"}},
+		{[]string{"clone", "--depth", ""}, nil},
+		{[]string{"cache", "u"}, []string{"update"}},
+		{[]string{"completion", ""}, []string{"bash", "zsh", "fish"}},
+		{[]string{"init", filepath.Join(root, "proj")}, []string{filepath.Join(root, "projects") + string(filepath.Separator)}},
+		{[]string{"init", "--shell", "p"}, []string{"powershell"}},
+		{[]string{"exec", "--filter", "is:w"}, []string{"is:worktree"}},
+		{[]string{"exec", "redis", "--", "re"}, nil},
+		{[]string{"unknown", ""}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := Complete(tt.words); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteHidesInternalCommands(t *testing.T) {
+	t.Setenv("TRY_PATH", t.TempDir())
+
+	for _, candidate := range Complete([]string{""}) {
+		if candidate == "__complete" {
+			t.Errorf("Complete offered the hidden __complete command")
+		}
+	}
+}
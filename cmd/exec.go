@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zengjie/try/core"
+)
+
+// ExecOptions holds the options of `try exec`
+type ExecOptions struct {
+	All    bool   // Run in every try matching Filter
+	Filter string // Filter expression, see core.Filter
+	Jobs   int    // Tries to run in at the same time, 0 for exec.jobs
+}
+
+const execUsage = "usage: try exec <query> -- <command...> or try exec --all [--filter <expr>] [-j <jobs>] -- <command...>"
+
+// ParseExecArgs parses `try exec [options] <query> -- <command...>`. The
+// query is every argument before -- that isn't an option.
+func ParseExecArgs(args []string) (string, []string, ExecOptions, error) {
+	var opts ExecOptions
+	var words []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			command := args[i+1:]
+			if len(command) == 0 {
+				return "", nil, opts, fmt.Errorf("%s", execUsage)
+			}
+			if opts.Filter != "" {
+				opts.All = true
+			}
+			query := strings.Join(words, " ")
+			if query == "" && !opts.All {
+				return "", nil, opts, fmt.Errorf("%s", execUsage)
+			}
+			return query, command, opts, nil
+		case arg == "--all" || arg == "-a":
+			opts.All = true
+		case arg == "--filter" || arg == "--jobs" || arg == "-j":
+			if i+1 >= len(args) {
+				return "", nil, opts, fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			if arg == "--filter" {
+				opts.Filter = args[i]
+			} else if err := opts.setJobs(args[i]); err != nil {
+				return "", nil, opts, err
+			}
+		case strings.HasPrefix(arg, "--filter="):
+			opts.Filter = strings.TrimPrefix(arg, "--filter=")
+		case strings.HasPrefix(arg, "--jobs="):
+			if err := opts.setJobs(strings.TrimPrefix(arg, "--jobs=")); err != nil {
+				return "", nil, opts, err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return "", nil, opts, fmt.Errorf("unknown option %s", arg)
+		default:
+			words = append(words, arg)
+		}
+	}
+
+	return "", nil, opts, fmt.Errorf("missing -- before the command; %s", execUsage)
+}
+
+func (o *ExecOptions) setJobs(value string) error {
+	jobs, err := strconv.Atoi(value)
+	if err != nil || jobs < 1 {
+		return fmt.Errorf("invalid number of jobs %q", value)
+	}
+	o.Jobs = jobs
+	return nil
+}
+
+// RunExec implements `try exec` and returns the exit code to leave with:
+// the command's own for a single try, and 1 if it failed in any of several
+func RunExec(args []string) (int, error) {
+	query, command, opts, err := ParseExecArgs(args)
+	if err != nil {
+		return 1, err
+	}
+
+	// Ctrl+C reaches the command too; wait for it to decide what to do
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	if opts.All {
+		return execAll(query, command, opts)
+	}
+
+	dir, err := core.FindDirectory(query)
+	if err != nil {
+		return 1, err
+	}
+
+	cmd := core.TryCommand(*dir, command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		code := core.ExitCode(err)
+		if code < 0 {
+			return 127, err
+		}
+		return code, nil
+	}
+	return 0, nil
+}
+
+// execAll runs command in every try matching the filter and query. Each
+// try's output is printed in one piece when it finishes, followed by a
+// summary table on stderr.
+func execAll(query string, command []string, opts ExecOptions) (int, error) {
+	filter, err := core.ParseFilter(strings.TrimSpace(opts.Filter + " " + query))
+	if err != nil {
+		return 1, err
+	}
+
+	directories, err := core.ScanDirectories()
+	if err != nil {
+		return 1, err
+	}
+	directories = core.FilterDirectories(directories, filter)
+	if len(directories) == 0 {
+		return 1, fmt.Errorf("no try matches the filter")
+	}
+	core.SortDirectoriesByTime(directories)
+
+	jobs := opts.Jobs
+	if jobs == 0 {
+		jobs = core.GetConfig().Int("exec.jobs", 4)
+	}
+
+	results := core.ExecInDirectories(directories, command, jobs, func(result core.ExecResult) {
+		fmt.Printf("==> %s (%s)\n", result.Dir.Name, execStatus(result))
+		os.Stdout.Write(result.Output)
+		if len(result.Output) > 0 && result.Output[len(result.Output)-1] != '\n' {
+			fmt.Println()
+		}
+	})
+
+	failed := 0
+	table := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "\nTRY\tSTATUS\tTIME")
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", result.Dir.Name, execStatus(result), result.Duration.Round(100*time.Millisecond))
+	}
+	table.Flush()
+	fmt.Fprintf(os.Stderr, "%d succeeded, %d failed\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func execStatus(result core.ExecResult) string {
+	switch {
+	case result.Err == nil:
+		return "ok"
+	case result.ExitCode < 0:
+		return result.Err.Error()
+	}
+	return fmt.Sprintf("exit %d", result.ExitCode)
+}
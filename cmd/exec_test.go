@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExecArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		query   string
+		command []string
+		opts    ExecOptions
+		wantErr bool
+	}{
+		{args: []string{"redis", "--", "go", "test", "./..."}, query: "redis", command: []string{"go", "test", "./..."}},
+		{args: []string{"redis", "cluster", "--", "ls", "--all"}, query: "redis cluster", command: []string{"ls", "--all"}},
+		{args: []string{"--all", "-j", "8", "--", "git", "fetch"}, command: []string{"git", "fetch"}, opts: ExecOptions{All: true, Jobs: 8}},
+		{args: []string{"--filter", "is:git", "--", "git", "fetch"}, command: []string{"git", "fetch"}, opts: ExecOptions{All: true, Filter: "is:git"}},
+		{args: []string{"redis", "git", "fetch"}, wantErr: true},
+		{args: []string{"--", "ls"}, wantErr: true},
+		{args: []string{"redis", "--"}, wantErr: true},
+		{args: []string{"--all", "--jobs=0", "--", "ls"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		query, command, opts, err := ParseExecArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseExecArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (query != tt.query || !reflect.DeepEqual(command, tt.command) || opts != tt.opts) {
+			t.Errorf("ParseExecArgs(%q) = %q, %q, %+v; want %q, %q, %+v", tt.args, query, command, opts, tt.query, tt.command, tt.opts)
+		}
+	}
+}
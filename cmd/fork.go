@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zengjie/try/core"
+)
+
+const forkUsage = "usage: try fork <query> [new-name] [--skip-artifacts] [--worktree]"
+
+// ParseForkArgs parses `try fork <query> [new-name] [options]`
+func ParseForkArgs(args []string) (string, core.ForkOptions, error) {
+	var opts core.ForkOptions
+	var positional []string
+
+	for _, arg := range args {
+		switch {
+		case arg == "--skip-artifacts":
+			opts.SkipArtifacts = true
+		case arg == "--worktree":
+			opts.Worktree = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return "", opts, fmt.Errorf("unknown option %s", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) < 1 || len(positional) > 2 {
+		return "", opts, fmt.Errorf("%s", forkUsage)
+	}
+	if opts.Worktree && opts.SkipArtifacts {
+		return "", opts, fmt.Errorf("--skip-artifacts has no effect with --worktree, which only checks out committed files")
+	}
+	if len(positional) == 2 {
+		opts.Name = positional[1]
+	}
+	return positional[0], opts, nil
+}
+
+// ForkDirectory copies the try best matching query into a new try
+func ForkDirectory(query string, opts core.ForkOptions) error {
+	dir, err := core.FindDirectory(query)
+	if err != nil {
+		return err
+	}
+
+	path, err := core.ForkDirectory(*dir, opts, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Forked %s to %s\n", dir.Name, filepath.Base(path))
+
+	// Hand the path to the shell so it can cd there
+	return changeDirectory(path, true)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/zengjie/try/core"
+)
+
+func TestParseForkArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		query   string
+		opts    core.ForkOptions
+		wantErr bool
+	}{
+		{args: []string{"redis"}, query: "redis"},
+		{args: []string{"redis", "redis-lru"}, query: "redis", opts: core.ForkOptions{Name: "redis-lru"}},
+		{args: []string{"--skip-artifacts", "web"}, query: "web", opts: core.ForkOptions{SkipArtifacts: true}},
+		{args: []string{"api", "api-v2", "--worktree"}, query: "api", opts: core.ForkOptions{Name: "api-v2", Worktree: true}},
+		{args: []string{"api", "--worktree", "--skip-artifacts"}, wantErr: true},
+		{args: []string{"a", "b", "c"}, wantErr: true},
+		{args: []string{"--shallow", "a"}, wantErr: true},
+		{args: []string{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		query, opts, err := ParseForkArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseForkArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (query != tt.query || opts != tt.opts) {
+			t.Errorf("ParseForkArgs(%q) = %q, %+v; want %q, %+v", tt.args, query, opts, tt.query, tt.opts)
+		}
+	}
+}
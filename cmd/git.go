@@ -1,39 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/zengjie/try/core"
 )
 
-func CloneRepository(url string) error {
-	repoName := core.ExtractNameFromGitURL(url)
-	dirName := core.GenerateDatedName(repoName)
-	fullPath := filepath.Join(core.GetTryPath(), dirName)
-	
-	if err := core.EnsureTryDirectory(); err != nil {
-		return fmt.Errorf("failed to ensure try directory: %w", err)
-	}
-	
-	cmd := exec.Command("git", "clone", url, fullPath)
-	cmd.Stdout = os.Stderr
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+func CloneRepository(source string, opts core.CloneOptions) error {
+	fullPath, err := core.CloneRepository(context.Background(), source, opts, os.Stderr)
+	if err != nil {
+		return err
 	}
 	
-	// Write to .try_cd file for shell integration
-	home, _ := os.UserHomeDir()
-	cdFile := filepath.Join(home, ".try_cd")
-	os.WriteFile(cdFile, []byte(fullPath), 0644)
-	
-	fmt.Println(fullPath)
-	return nil
+	// Hand the path to the shell so it can cd there
+	return changeDirectory(fullPath, true)
 }
 
 func CreateWorktree(repoPath string, name string) error {
@@ -42,6 +26,13 @@ func CreateWorktree(repoPath string, name string) error {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 	
+	// A try name works as well as a path, which is what completion offers
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		if dir, findErr := core.FindDirectory(repoPath); findErr == nil {
+			absPath = dir.TargetPath()
+		}
+	}
+	
 	if !isGitRepository(absPath) {
 		return fmt.Errorf("%s is not a git repository", absPath)
 	}
@@ -50,47 +41,26 @@ func CreateWorktree(repoPath string, name string) error {
 		name = filepath.Base(absPath) + "-worktree"
 	}
 	
-	dirName := core.GenerateDatedName(name)
-	fullPath := filepath.Join(core.GetTryPath(), dirName)
-	
-	if err := core.EnsureTryDirectory(); err != nil {
-		return fmt.Errorf("failed to ensure try directory: %w", err)
-	}
-	
 	gitDir := findGitDir(absPath)
 	if gitDir == "" {
 		return fmt.Errorf("could not find .git directory for %s", absPath)
 	}
 	
-	cmd := exec.Command("git", "worktree", "add", "--detach", fullPath)
-	cmd.Dir = filepath.Dir(gitDir)
-	cmd.Stdout = os.Stderr
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
+	fullPath, err := core.CreateWorktree(filepath.Dir(gitDir), name, "")
+	if err != nil {
 		if strings.Contains(err.Error(), "not a git repository") {
 			path, err := core.CreateDirectory(name)
 			if err != nil {
 				return err
 			}
-			// Write to .try_cd file for shell integration
-			home, _ := os.UserHomeDir()
-			cdFile := filepath.Join(home, ".try_cd")
-			os.WriteFile(cdFile, []byte(path), 0644)
-			
-			fmt.Println(path)
-			return nil
+			// Hand the path to the shell so it can cd there
+			return changeDirectory(path, true)
 		}
-		return fmt.Errorf("failed to create worktree: %w", err)
+		return err
 	}
 	
-	// Write to .try_cd file for shell integration
-	home, _ := os.UserHomeDir()
-	cdFile := filepath.Join(home, ".try_cd")
-	os.WriteFile(cdFile, []byte(fullPath), 0644)
-	
-	fmt.Println(fullPath)
-	return nil
+	// Hand the path to the shell so it can cd there
+	return changeDirectory(fullPath, true)
 }
 
 func isGitRepository(path string) bool {
@@ -130,4 +100,25 @@ func findGitDir(path string) string {
 	}
 	
 	return ""
-}
\ No newline at end of file
+}
+
+// DetachWorktree turns the worktree best matching query into a standalone
+// repository
+func DetachWorktree(query string) error {
+	dir, err := core.FindDirectory(query)
+	if err != nil {
+		return err
+	}
+	
+	if !dir.IsWorktree {
+		return fmt.Errorf("%s is not a git worktree", dir.Name)
+	}
+	
+	if err := core.DetachWorktree(dir.Path); err != nil {
+		return fmt.Errorf("failed to detach worktree: %w", err)
+	}
+	
+	fmt.Fprintf(os.Stderr, "Detached %s into a standalone repository\n", dir.Name)
+	fmt.Println(dir.Path)
+	return nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zengjie/try/core"
+)
+
+// RunHooks implements `try hooks allow|deny <try>`, which decides whether a
+// try's own .try/hooks may run
+func RunHooks(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: try hooks allow|deny <try>")
+	}
+
+	dir, err := core.FindDirectory(args[1])
+	if err != nil {
+		return err
+	}
+	path := dir.TargetPath()
+
+	switch args[0] {
+	case "allow":
+		if err := core.AllowTryHooks(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Allowed the hooks of %s, they need allowing again when they change\n", dir.Name)
+		return nil
+	case "deny":
+		if err := core.DenyTryHooks(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Hooks of %s won't run anymore\n", dir.Name)
+		return nil
+	}
+
+	return fmt.Errorf("unknown hooks command %s", args[0])
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zengjie/try/core"
+	"github.com/zengjie/try/shell"
+)
+
+// RunInit implements `try init [path] [--shell <name>] [--cmd <name>]`. The
+// shell defaults to $SHELL, falling back to bash when it isn't supported.
+// Without a path the wrapper leaves the location to the config, so the same
+// line works in dotfiles shared across machines.
+func RunInit(args []string) error {
+	var tryPath, shellName, command string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--shell" || arg == "--cmd":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a name", arg)
+			}
+			i++
+			if arg == "--shell" {
+				shellName = args[i]
+			} else {
+				command = args[i]
+			}
+		case strings.HasPrefix(arg, "--shell="):
+			shellName = strings.TrimPrefix(arg, "--shell=")
+		case strings.HasPrefix(arg, "--cmd="):
+			command = strings.TrimPrefix(arg, "--cmd=")
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return fmt.Errorf("unknown option %s", arg)
+		default:
+			tryPath = arg
+		}
+	}
+
+	if tryPath != "" {
+		abs, err := filepath.Abs(core.ExpandHome(tryPath))
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", tryPath, err)
+		}
+		tryPath = abs
+	}
+
+	if shellName == "" {
+		detected, ok := shell.NormalizeShell(os.Getenv("SHELL"))
+		if !ok {
+			detected = "bash"
+		}
+		shellName = detected
+	}
+
+	script, err := shell.Generate(shell.Options{
+		Shell:   shellName,
+		TryPath: tryPath,
+		Binary:  pinnedBinary(),
+		Command: command,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+// pinnedBinary returns the running binary when it can't be found on PATH,
+// e.g. when it was started as ./try. Otherwise the wrapper looks try up each
+// time it runs, so the binary can be upgraded or moved freely.
+func pinnedBinary() string {
+	if _, err := exec.LookPath("try"); err == nil {
+		return ""
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return executable
+}
+
+// CheckWrapper warns on w when try was started by a shell wrapper generated
+// for a different version, which happens when the binary is upgraded under
+// a running shell. Wrappers set TRY_WRAPPER to their version; those from
+// before versioning only set TRY_CD_FILE.
+func CheckWrapper(w io.Writer) {
+	version := os.Getenv("TRY_WRAPPER")
+	if version == "" && os.Getenv(core.CdFileEnv) == "" {
+		return
+	}
+	if version == strconv.Itoa(shell.WrapperVersion) {
+		return
+	}
+
+	fmt.Fprintln(w, `try: shell integration is out of date with this binary; restart your shell or re-run eval "$(try init)"`)
+}
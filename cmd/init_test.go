@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/zengjie/try/shell"
+)
+
+func TestCheckWrapper(t *testing.T) {
+	current := strconv.Itoa(shell.WrapperVersion)
+	tests := []struct {
+		wrapper string
+		cdFile  string
+		warn    bool
+	}{
+		{wrapper: "", cdFile: "", warn: false},
+		{wrapper: current, cdFile: "/tmp/try-cd.1", warn: false},
+		{wrapper: "", cdFile: "/tmp/try-cd.1", warn: true},
+		{wrapper: "999", cdFile: "/tmp/try-cd.1", warn: true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TRY_WRAPPER", tt.wrapper)
+		t.Setenv("TRY_CD_FILE", tt.cdFile)
+
+		var out bytes.Buffer
+		CheckWrapper(&out)
+		if warned := out.Len() > 0; warned != tt.warn {
+			t.Errorf("CheckWrapper with TRY_WRAPPER=%q TRY_CD_FILE=%q warned = %v, want %v", tt.wrapper, tt.cdFile, warned, tt.warn)
+		}
+	}
+}
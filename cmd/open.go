@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zengjie/try/core"
+)
+
+// OpenOptions holds the options of `try open`
+type OpenOptions struct {
+	Multiplexer string // "tmux" or "zellij", empty for core.DefaultMultiplexer
+	With        string // Open in this editor instead of a session
+	Cd          bool   // Also cd into the (first) try
+}
+
+// ParseOpenArgs parses `try open <query>... [--tmux|--zellij|--with <name>]
+// [--cd]`. Every argument that isn't a flag is a query for one try.
+func ParseOpenArgs(args []string) ([]string, OpenOptions, error) {
+	var opts OpenOptions
+	var queries []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--tmux":
+			opts.Multiplexer = core.MultiplexerTmux
+		case arg == "--zellij":
+			opts.Multiplexer = core.MultiplexerZellij
+		case arg == "--with" || arg == "-w":
+			if i+1 >= len(args) {
+				return nil, opts, fmt.Errorf("%s requires an editor name", arg)
+			}
+			i++
+			opts.With = args[i]
+		case strings.HasPrefix(arg, "--with="):
+			opts.With = strings.TrimPrefix(arg, "--with=")
+		case arg == "--cd":
+			opts.Cd = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return nil, opts, fmt.Errorf("unknown option %s", arg)
+		default:
+			queries = append(queries, arg)
+		}
+	}
+
+	if len(queries) == 0 {
+		return nil, opts, fmt.Errorf("usage: try open <query>... [--tmux|--zellij|--with <editor>] [--cd]")
+	}
+	if opts.With != "" && opts.Multiplexer != "" {
+		return nil, opts, fmt.Errorf("--with can't be combined with --%s", opts.Multiplexer)
+	}
+	if opts.With == "" && len(queries) > 1 {
+		return nil, opts, fmt.Errorf("sessions open one try at a time; use --with to open several in an editor")
+	}
+	return queries, opts, nil
+}
+
+// OpenDirectories opens the tries best matching queries in an editor, or
+// the single try in a multiplexer session. The shell only changes
+// directory when opts.Cd asks for it.
+func OpenDirectories(queries []string, opts OpenOptions) error {
+	var paths []string
+	for _, query := range queries {
+		dir, err := core.FindDirectory(query)
+		if err != nil {
+			return err
+		}
+		path := dir.TargetPath()
+		if err := core.RunHooks(core.HookOnEnter, path, os.Stderr); err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	if opts.With == "" {
+		if err := OpenSession(opts.Multiplexer, paths[0]); err != nil {
+			return err
+		}
+	} else {
+		opener, err := core.FindOpener(opts.With)
+		if err != nil {
+			return err
+		}
+		if err := opener.Open(paths); err != nil {
+			return err
+		}
+	}
+
+	if opts.Cd {
+		return changeDirectory(paths[0], false)
+	}
+	return nil
+}
+
+// OpenSession opens path in multiplexer, or the default one when empty
+func OpenSession(multiplexer, path string) error {
+	if multiplexer == "" {
+		multiplexer = core.DefaultMultiplexer()
+	}
+	return core.OpenSession(multiplexer, path)
+}
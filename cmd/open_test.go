@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOpenArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		queries []string
+		opts    OpenOptions
+		wantErr bool
+	}{
+		{args: []string{"redis", "--tmux"}, queries: []string{"redis"}, opts: OpenOptions{Multiplexer: "tmux"}},
+		{args: []string{"--zellij", "api"}, queries: []string{"api"}, opts: OpenOptions{Multiplexer: "zellij"}},
+		{args: []string{"redis", "api", "--with", "code"}, queries: []string{"redis", "api"}, opts: OpenOptions{With: "code"}},
+		{args: []string{"redis", "--with=vim", "--cd"}, queries: []string{"redis"}, opts: OpenOptions{With: "vim", Cd: true}},
+		{args: []string{"redis", "api"}, wantErr: true},
+		{args: []string{"redis", "--with", "code", "--tmux"}, wantErr: true},
+		{args: []string{"redis", "--with"}, wantErr: true},
+		{args: []string{"--tmux"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		queries, opts, err := ParseOpenArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseOpenArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (!reflect.DeepEqual(queries, tt.queries) || opts != tt.opts) {
+			t.Errorf("ParseOpenArgs(%q) = %q, %+v; want %q, %+v", tt.args, queries, opts, tt.queries, tt.opts)
+		}
+	}
+}
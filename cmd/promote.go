@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zengjie/try/core"
+)
+
+func PromoteDirectory(query string, opts core.PromoteOptions) error {
+	dir, err := core.FindDirectory(query)
+	if err != nil {
+		return err
+	}
+
+	path, err := core.PromoteDirectory(*dir, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Promoted %s to %s\n", dir.Name, path)
+
+	// Hand the path to the shell so it can cd there
+	return changeDirectory(path, true)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zengjie/try/core"
+)
+
+// RenameDirectory renames the try best matching query. When the shell is
+// inside the try it is sent to the same place under the new name.
+func RenameDirectory(query, name string) error {
+	dir, err := core.FindDirectory(query)
+	if err != nil {
+		return err
+	}
+
+	// Find out before the move, the old path won't resolve afterwards
+	wd, _ := os.Getwd()
+	inside, _ := filepath.Rel(dir.Path, wd)
+
+	path, err := core.RenameDirectory(*dir, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Renamed %s to %s\n", dir.Name, filepath.Base(path))
+
+	if wd == "" || dir.IsAlias || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return changeDirectory(filepath.Join(path, inside), false)
+}
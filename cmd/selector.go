@@ -1,16 +1,62 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/zengjie/try/core"
 	"github.com/zengjie/try/ui"
 )
 
-func RunInteractiveSelector(initialQuery string) error {
+// SelectorOptions controls how the interactive selector is shown
+type SelectorOptions struct {
+	Inline bool // Draw below the prompt instead of on the alternate screen
+	Height int  // Maximum height in lines, 0 for the whole terminal
+	Print  bool // Print the chosen path to stdout instead of handing it to the shell wrapper
+}
+
+// ParseSelectorArgs parses `try [query] [--inline] [--height N] [--print]`.
+// Everything that isn't a flag is joined into the query.
+func ParseSelectorArgs(args []string) (string, SelectorOptions, error) {
+	var opts SelectorOptions
+	var words []string
+	
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--inline":
+			opts.Inline = true
+		case arg == "--print":
+			opts.Print = true
+		case arg == "--height" || strings.HasPrefix(arg, "--height="):
+			value := strings.TrimPrefix(arg, "--height=")
+			if arg == "--height" {
+				if i+1 >= len(args) {
+					return "", opts, fmt.Errorf("--height requires a number of lines")
+				}
+				i++
+				value = args[i]
+			}
+			height, err := strconv.Atoi(value)
+			if err != nil || height < 1 {
+				return "", opts, fmt.Errorf("invalid --height %q", value)
+			}
+			opts.Height = height
+		default:
+			words = append(words, arg)
+		}
+	}
+	
+	return strings.Join(words, " "), opts, nil
+}
+
+func RunInteractiveSelector(initialQuery string, opts SelectorOptions) error {
 	if err := core.EnsureTryDirectory(); err != nil {
 		return fmt.Errorf("failed to ensure try directory: %w", err)
 	}
@@ -20,41 +66,153 @@ func RunInteractiveSelector(initialQuery string) error {
 		return fmt.Errorf("not running in an interactive terminal")
 	}
 
+	keys, err := ui.LoadKeyMap()
+	if err != nil {
+		return err
+	}
+	if opts.Print {
+		// stdout is captured, e.g. by the Alt+T widget, so a tmux or zellij
+		// session has no terminal to attach to
+		keys.Session.SetEnabled(false)
+	}
+	columns, err := ui.LoadColumns()
+	if err != nil {
+		return err
+	}
+	
+	// stdout is reserved for the result with --print, e.g. when captured by
+	// $(try --print), so the selector draws on stderr and picks colors for it
+	renderer := lipgloss.DefaultRenderer()
+	if opts.Print {
+		renderer = lipgloss.NewRenderer(os.Stderr)
+	}
+	theme, err := ui.LoadTheme(renderer.HasDarkBackground)
+	if err != nil {
+		return err
+	}
+	ui.SetTheme(theme)
+	
 	m := ui.NewModel()
+	m.SetKeyMap(keys)
+	m.SetColumns(columns)
 	m.SetQuery(initialQuery)
+	if opts.Inline {
+		m.SetInline()
+	}
+	if opts.Height > 0 {
+		m.SetMaxHeight(opts.Height)
+	}
 	if err := m.LoadDirectories(); err != nil {
 		return fmt.Errorf("failed to load directories: %w", err)
 	}
 	
+	// Hooks must not draw over the selector, so their output is shown once
+	// it has exited
+	var hookOutput bytes.Buffer
+	core.HookOutput = &hookOutput
+	
 	// Create program with input/output options
-	p := tea.NewProgram(
-		m,
-		tea.WithAltScreen(),
-	)
+	var programOpts []tea.ProgramOption
+	if !opts.Inline {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	if opts.Print {
+		programOpts = append(programOpts, tea.WithOutput(os.Stderr))
+		lipgloss.SetColorProfile(renderer.ColorProfile())
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		// The theme has no colors, but bold and reverse video still mark
+		// the selected row, which the plain text profile would drop
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+	p := tea.NewProgram(m, programOpts...)
 	
-	_, err := p.Run()
+	final, err := p.Run()
+	core.HookOutput = os.Stderr
+	os.Stderr.Write(hookOutput.Bytes())
 	if err != nil {
 		return fmt.Errorf("failed to run selector: %w", err)
 	}
 	
-	// The program will handle output through messages
-	return nil
+	m, ok := final.(ui.Model)
+	if !ok || m.SelectedPath() == "" {
+		return nil
+	}
+	if opts.Print {
+		fmt.Println(m.SelectedPath())
+		return nil
+	}
+	if m.SelectedAction() == ui.ActionSession {
+		return OpenSession("", m.SelectedPath())
+	}
+	return changeDirectory(m.SelectedPath(), false)
+}
+
+// NewOptions holds the options of `try new`
+type NewOptions struct {
+	Template string
+	Lang     string // Scaffold a project for this language, e.g. "go"
+	Commit   bool   // Make an initial commit after scaffolding
+}
+
+// ParseNewArgs parses `try new` arguments. Everything that isn't a flag is
+// joined into the name.
+func ParseNewArgs(args []string) (string, NewOptions, error) {
+	var opts NewOptions
+	var words []string
+	
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--template" || arg == "-t":
+			if i+1 >= len(args) {
+				return "", opts, fmt.Errorf("%s requires a template name", arg)
+			}
+			i++
+			opts.Template = args[i]
+		case strings.HasPrefix(arg, "--template="):
+			opts.Template = strings.TrimPrefix(arg, "--template=")
+		case arg == "--lang" || arg == "-l":
+			if i+1 >= len(args) {
+				return "", opts, fmt.Errorf("%s requires a language (%s)", arg, strings.Join(core.ScaffoldLanguages(), ", "))
+			}
+			i++
+			opts.Lang = args[i]
+		case strings.HasPrefix(arg, "--lang="):
+			opts.Lang = strings.TrimPrefix(arg, "--lang=")
+		case arg == "--commit":
+			opts.Commit = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return "", opts, fmt.Errorf("unknown option %s", arg)
+		default:
+			words = append(words, arg)
+		}
+	}
+	
+	if opts.Commit && opts.Lang == "" {
+		return "", opts, fmt.Errorf("--commit requires --lang")
+	}
+	if opts.Lang != "" {
+		if _, err := core.ScaffoldLanguage(opts.Lang); err != nil {
+			return "", opts, err
+		}
+	}
+	
+	return strings.Join(words, " "), opts, nil
 }
 
-func CreateNewDirectory(name string) error {
-	path, err := core.CreateDirectory(name)
+func CreateNewDirectory(name string, opts NewOptions) error {
+	path, err := core.CreateDirectoryWith(name, core.CreateOptions{
+		Template: opts.Template,
+		Lang:     opts.Lang,
+		Commit:   opts.Commit,
+	}, os.Stderr)
 	if err != nil {
 		return err
 	}
 	
-	// Write to .try_cd file for shell integration
-	home, _ := os.UserHomeDir()
-	cdFile := filepath.Join(home, ".try_cd")
-	os.WriteFile(cdFile, []byte(path), 0644)
-	
-	// Also print to stdout for backward compatibility
-	fmt.Println(path)
-	return nil
+	// Hand the path to the shell so it can cd there
+	return changeDirectory(path, true)
 }
 
 func isInteractive() bool {
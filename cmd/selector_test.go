@@ -0,0 +1,57 @@
+package cmd
+
+import "testing"
+
+func TestParseSelectorArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		query   string
+		opts    SelectorOptions
+		wantErr bool
+	}{
+		{args: []string{"redis", "cluster"}, query: "redis cluster"},
+		{args: []string{"--print", "--inline", "--height", "15"}, opts: SelectorOptions{Inline: true, Height: 15, Print: true}},
+		{args: []string{"api", "--height=10", "--inline"}, query: "api", opts: SelectorOptions{Inline: true, Height: 10}},
+		{args: []string{"--height"}, wantErr: true},
+		{args: []string{"--height", "0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		query, opts, err := ParseSelectorArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSelectorArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (query != tt.query || opts != tt.opts) {
+			t.Errorf("ParseSelectorArgs(%q) = %q, %+v; want %q, %+v", tt.args, query, opts, tt.query, tt.opts)
+		}
+	}
+}
+
+func TestParseNewArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		name    string
+		opts    NewOptions
+		wantErr bool
+	}{
+		{args: []string{"redis", "bench"}, name: "redis bench"},
+		{args: []string{"api", "--lang", "go", "--commit"}, name: "api", opts: NewOptions{Lang: "go", Commit: true}},
+		{args: []string{"--lang=golang", "-t", "web", "site"}, name: "site", opts: NewOptions{Lang: "golang", Template: "web"}},
+		{args: []string{"foo", "--lang", "cobol"}, wantErr: true},
+		{args: []string{"foo", "--lang"}, wantErr: true},
+		{args: []string{"foo", "--commit"}, wantErr: true},
+		{args: []string{"foo", "--bogus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		name, opts, err := ParseNewArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseNewArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (name != tt.name || opts != tt.opts) {
+			t.Errorf("ParseNewArgs(%q) = %q, %+v; want %q, %+v", tt.args, name, opts, tt.name, tt.opts)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zengjie/try/core"
+)
+
+// ListTemplates implements `try templates`
+func ListTemplates() error {
+	templates, err := core.ListTemplates()
+	if err != nil {
+		return err
+	}
+
+	if len(templates) == 0 {
+		fmt.Fprintf(os.Stderr, "No templates yet. Add directories to %s\n", core.TemplatesDir())
+		return nil
+	}
+
+	for _, template := range templates {
+		if template.HasHook {
+			fmt.Printf("%-20s (post-create hook)\n", template.Name)
+		} else {
+			fmt.Println(template.Name)
+		}
+	}
+	return nil
+}
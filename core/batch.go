@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Hidden directories inside the tries directory that tries are moved into.
+// The scanner skips hidden directories, so moved tries leave the selector.
+const (
+	TrashDirName   = ".trash"
+	ArchiveDirName = ".archive"
+)
+
+// TrySummary describes a try about to be changed by a batch action
+type TrySummary struct {
+	Dir   Directory
+	Size  int64
+	Dirty bool // Uncommitted changes in a repository or worktree
+}
+
+// SummarizeDirectories measures every directory and checks for uncommitted
+// work. Promoted aliases count as the link only, which is all that a delete,
+// trash or archive touches.
+func SummarizeDirectories(directories []Directory) []TrySummary {
+	summaries := make([]TrySummary, len(directories))
+	for i, dir := range directories {
+		summaries[i] = TrySummary{Dir: dir, Dirty: isDirty(dir)}
+		if !dir.IsAlias {
+			summaries[i].Size = DirectorySize(dir.Path)
+		}
+	}
+	return summaries
+}
+
+// TrashDirectory moves a try into the .trash directory of the tries
+// directory and returns its new path
+func TrashDirectory(path string) (string, error) {
+	return moveInto(TrashDirName, path)
+}
+
+// ArchiveDirectory moves a try into the .archive directory of the tries
+// directory and returns its new path
+func ArchiveDirectory(path string) (string, error) {
+	return moveInto(ArchiveDirName, path)
+}
+
+func moveInto(hidden, path string) (string, error) {
+	tryPath := GetTryPath()
+	if filepath.Dir(path) != filepath.Clean(tryPath) {
+		return "", fmt.Errorf("can only move directories within %s", tryPath)
+	}
+
+	dir := filepath.Join(tryPath, hidden)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	target := filepath.Join(dir, uniqueName(dir, filepath.Base(path)))
+	if err := moveTry(path, target); err != nil {
+		return "", err
+	}
+
+	// Tags travel with the try, e.g. for when it is moved back
+	if err := UpdateState(func(state *State) { state.Move(path, target) }); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return target, nil
+}
+
+// uniqueName returns name, or name with the first free -1, -2, ... suffix
+// when dir already has an entry called name
+func uniqueName(dir, name string) string {
+	candidate := name
+	for counter := 1; ; counter++ {
+		if _, err := os.Lstat(filepath.Join(dir, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, counter)
+	}
+}
+
+// FetchDirectories runs git fetch in every repository and worktree among
+// directories, at most jobs at a time, and returns the results of those it
+// ran in. Git is told not to prompt for credentials, since nobody would be
+// there to answer, and is stopped when ctx is cancelled.
+func FetchDirectories(ctx context.Context, directories []Directory, jobs int) []ExecResult {
+	var repos []Directory
+	for _, dir := range directories {
+		if dir.IsGitRepo || dir.IsWorktree {
+			repos = append(repos, dir)
+		}
+	}
+
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	return execInDirectories(ctx, repos, []string{"git", "fetch", "--all", "--prune"}, env, jobs, nil)
+}
+
+// FailedNames lists the tries whose command failed, for status messages
+func FailedNames(results []ExecResult) string {
+	var names []string
+	for _, result := range results {
+		if result.ExitCode != 0 {
+			names = append(names, result.Dir.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrashAndArchive(t *testing.T) {
+	root := t.TempDir()
+	tries := filepath.Join(root, "tries")
+	t.Setenv("TRY_PATH", tries)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+
+	for _, name := range []string{"2025-08-30-redis", "2025-08-30-notes"} {
+		os.MkdirAll(filepath.Join(tries, name), 0755)
+	}
+	// An earlier try of the same name is already in the trash
+	os.MkdirAll(filepath.Join(tries, TrashDirName, "2025-08-30-redis"), 0755)
+
+	trashed, err := TrashDirectory(filepath.Join(tries, "2025-08-30-redis"))
+	if err != nil {
+		t.Fatalf("TrashDirectory failed: %v", err)
+	}
+	if want := filepath.Join(tries, TrashDirName, "2025-08-30-redis-1"); trashed != want {
+		t.Errorf("trashed to %s, want %s", trashed, want)
+	}
+
+	archived, err := ArchiveDirectory(filepath.Join(tries, "2025-08-30-notes"))
+	if err != nil {
+		t.Fatalf("ArchiveDirectory failed: %v", err)
+	}
+	if want := filepath.Join(tries, ArchiveDirName, "2025-08-30-notes"); archived != want {
+		t.Errorf("archived to %s, want %s", archived, want)
+	}
+
+	// Neither shows up as a try anymore
+	dirs, err := ScanDirectories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 0 {
+		t.Errorf("ScanDirectories() = %v, want no tries", dirs)
+	}
+
+	if _, err := TrashDirectory(filepath.Join(root, "elsewhere")); err == nil {
+		t.Error("TrashDirectory outside the tries folder succeeded, want error")
+	}
+}
+
+func TestTrashWorktree(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	worktree := filepath.Join(tries, "2025-08-30-repo-worktree")
+	gitCommand(t, repo, "worktree", "add", "--quiet", "-b", "feature", worktree)
+
+	trashed, err := TrashDirectory(worktree)
+	if err != nil {
+		t.Fatalf("TrashDirectory failed: %v", err)
+	}
+
+	// The repository still knows where its worktree is
+	list := gitCommand(t, repo, "worktree", "list", "--porcelain")
+	if want := "worktree " + trashed; !contains(strings.Split(list, "\n"), want) {
+		t.Errorf("git worktree list = %q, want %q", list, want)
+	}
+}
+
+func TestSummarizeDirectories(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	notes := filepath.Join(tries, "2025-08-30-notes")
+	os.MkdirAll(notes, 0755)
+	os.WriteFile(filepath.Join(notes, "todo"), make([]byte, 1000), 0644)
+
+	summaries := SummarizeDirectories([]Directory{
+		{Path: repo, IsGitRepo: true},
+		{Path: notes},
+	})
+	if summaries[0].Dirty {
+		t.Error("fresh clone reported dirty")
+	}
+	if summaries[1].Size != 1000 {
+		t.Errorf("notes size = %d, want 1000", summaries[1].Size)
+	}
+
+	os.WriteFile(filepath.Join(repo, "README"), []byte("changed\n"), 0644)
+	if summaries := SummarizeDirectories([]Directory{{Path: repo, IsGitRepo: true}}); !summaries[0].Dirty {
+		t.Error("modified clone not reported dirty")
+	}
+}
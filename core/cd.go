@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CdFileEnv names the variable the shell wrapper uses to pass a fresh,
+// per-invocation file that receives the directory to cd into
+const CdFileEnv = "TRY_CD_FILE"
+
+// How try takes the shell to a try when the wrapper isn't loaded, set with
+// the cd-mode config key
+const (
+	CdModeSubshell = "subshell" // Start $SHELL inside the try
+	CdModePrint    = "print"    // Print the path for scripts to pick up
+	CdModeFile     = "file"     // Write ~/.try_cd, read by custom wrappers
+)
+
+// HasWrapper reports whether try was started by the shell wrapper from
+// `try init`. Current wrappers set TRY_WRAPPER and TRY_CD_FILE; those from
+// before TRY_CD_FILE only exported TRY_BINARY.
+func HasWrapper() bool {
+	return os.Getenv("TRY_WRAPPER") != "" || os.Getenv(CdFileEnv) != "" || os.Getenv("TRY_BINARY") != ""
+}
+
+// CdMode returns the cd-mode config key, defaulting to a subshell
+func CdMode() (string, error) {
+	mode := GetConfig().String("cd-mode", CdModeSubshell)
+	switch mode {
+	case CdModeSubshell, CdModePrint, CdModeFile:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid cd-mode %q (use %s, %s or %s)", mode, CdModeSubshell, CdModePrint, CdModeFile)
+}
+
+// WriteCdPath hands path to the shell wrapper, which changes into it once
+// try exits. Without TRY_CD_FILE the path goes to the shared ~/.try_cd,
+// which wrappers from older versions read.
+func WriteCdPath(path string) error {
+	cdFile := os.Getenv(CdFileEnv)
+	if cdFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to find home directory: %w", err)
+		}
+		cdFile = filepath.Join(home, ".try_cd")
+	}
+
+	if err := os.WriteFile(cdFile, []byte(path), 0600); err != nil {
+		return fmt.Errorf("failed to write cd path: %w", err)
+	}
+
+	return nil
+}
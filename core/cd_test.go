@@ -0,0 +1,77 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteCdPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cdFile := filepath.Join(t.TempDir(), "try-cd.123")
+	t.Setenv(CdFileEnv, cdFile)
+
+	if err := WriteCdPath("/tmp/tries/2025-08-30-foo"); err != nil {
+		t.Fatalf("WriteCdPath failed: %v", err)
+	}
+
+	if got, _ := os.ReadFile(cdFile); string(got) != "/tmp/tries/2025-08-30-foo" {
+		t.Errorf("%s = %q, want the path", cdFile, got)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".try_cd")); !os.IsNotExist(err) {
+		t.Errorf("~/.try_cd was written although %s is set", CdFileEnv)
+	}
+
+	// Wrappers from older versions still read ~/.try_cd
+	t.Setenv(CdFileEnv, "")
+	if err := WriteCdPath("/tmp/tries/2025-08-30-bar"); err != nil {
+		t.Fatalf("WriteCdPath failed: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(home, ".try_cd")); string(got) != "/tmp/tries/2025-08-30-bar" {
+		t.Errorf("~/.try_cd = %q, want the path", got)
+	}
+}
+
+func TestHasWrapper(t *testing.T) {
+	for _, env := range []string{"TRY_WRAPPER", CdFileEnv, "TRY_BINARY"} {
+		t.Setenv(env, "")
+	}
+	if HasWrapper() {
+		t.Error("HasWrapper() = true without any wrapper variables")
+	}
+
+	for _, env := range []string{"TRY_WRAPPER", CdFileEnv, "TRY_BINARY"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "1")
+			if !HasWrapper() {
+				t.Errorf("HasWrapper() = false with %s set", env)
+			}
+		})
+	}
+}
+
+func TestCdMode(t *testing.T) {
+	tests := []struct {
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{config: "", want: CdModeSubshell},
+		{config: "cd-mode: print\n", want: CdModePrint},
+		{config: "cd-mode: file\n", want: CdModeFile},
+		{config: "cd-mode: teleport\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		config := filepath.Join(t.TempDir(), "config")
+		os.WriteFile(config, []byte(tt.config), 0644)
+		t.Setenv("TRY_CONFIG", config)
+
+		mode, err := CdMode()
+		if (err != nil) != tt.wantErr || mode != tt.want {
+			t.Errorf("CdMode() with %q = %q, %v; want %q", tt.config, mode, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloneOptions controls how a repository is cloned. Team defaults come from
+// the clone.* config keys and can be overridden per clone with flags.
+type CloneOptions struct {
+	Depth             int      // --depth, 0 for full history
+	Filter            string   // --filter, e.g. blob:none
+	Branch            string   // --branch
+	RecurseSubmodules bool     // --recurse-submodules
+	Sparse            []string // Paths for a cone-mode sparse checkout
+	IncludeOwner      bool     // Name the clone owner-repo instead of repo
+	UseCache          bool     // Borrow objects from a cached mirror
+	Progress          bool     // Force progress output even when output is not a terminal
+}
+
+// DefaultCloneOptions returns the clone options configured in the config
+// file:
+//
+//	clone.depth: 1
+//	clone.filter: blob:none
+//	clone.branch: main
+//	clone.recurse-submodules: true
+//	clone.sparse: docs, tools
+//	clone.include-owner: true
+//	cache.enabled: true
+func DefaultCloneOptions() CloneOptions {
+	config := GetConfig()
+	return CloneOptions{
+		Depth:             config.Int("clone.depth", 0),
+		Filter:            config.String("clone.filter", ""),
+		Branch:            config.String("clone.branch", ""),
+		RecurseSubmodules: config.Bool("clone.recurse-submodules", false),
+		Sparse:            config.List("clone.sparse"),
+		IncludeOwner:      config.Bool("clone.include-owner", false),
+		UseCache:          config.Bool("cache.enabled", false),
+	}
+}
+
+// ParseCloneArgs parses `try clone` arguments on top of the configured
+// defaults. It is shared by the command line and the TUI clone prompt.
+func ParseCloneArgs(args []string) (string, CloneOptions, error) {
+	opts := DefaultCloneOptions()
+	source := ""
+	// Paths given with --sparse replace the configured ones
+	sparseGiven := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, value, hasValue := strings.Cut(arg, "=")
+
+		// Fetch the value of a flag given as either --flag=value or --flag value
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flag)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch flag {
+		case "--depth":
+			v, err := nextValue()
+			if err != nil {
+				return "", opts, err
+			}
+			depth, err := strconv.Atoi(v)
+			if err != nil || depth < 0 {
+				return "", opts, fmt.Errorf("invalid depth %q", v)
+			}
+			opts.Depth = depth
+		case "--full":
+			opts.Depth = 0
+			opts.Filter = ""
+		case "--filter":
+			v, err := nextValue()
+			if err != nil {
+				return "", opts, err
+			}
+			opts.Filter = v
+		case "--branch", "-b":
+			v, err := nextValue()
+			if err != nil {
+				return "", opts, err
+			}
+			opts.Branch = v
+		case "--recurse-submodules":
+			opts.RecurseSubmodules = true
+		case "--no-recurse-submodules":
+			opts.RecurseSubmodules = false
+		case "--sparse":
+			// One path per --sparse, or several separated by commas
+			v, err := nextValue()
+			if err != nil {
+				return "", opts, err
+			}
+			var paths []string
+			for _, path := range strings.Split(v, ",") {
+				if path = strings.TrimSpace(path); path != "" {
+					paths = append(paths, path)
+				}
+			}
+			if len(paths) == 0 {
+				return "", opts, fmt.Errorf("--sparse requires a path")
+			}
+			if !sparseGiven {
+				opts.Sparse = nil
+				sparseGiven = true
+			}
+			opts.Sparse = append(opts.Sparse, paths...)
+		case "--no-sparse":
+			opts.Sparse = nil
+			sparseGiven = true
+		case "--owner":
+			opts.IncludeOwner = true
+		case "--no-owner":
+			opts.IncludeOwner = false
+		case "--cache":
+			opts.UseCache = true
+		case "--no-cache":
+			opts.UseCache = false
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return "", opts, fmt.Errorf("unknown clone option %s", arg)
+			}
+			if source != "" {
+				return "", opts, fmt.Errorf("unexpected argument %s", arg)
+			}
+			source = arg
+		}
+	}
+
+	if source == "" {
+		return "", opts, fmt.Errorf("git URL required")
+	}
+
+	return source, opts, nil
+}
+
+// gitArgs returns the git clone flags for the options
+func (o CloneOptions) gitArgs() []string {
+	var args []string
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, "--filter", o.Filter)
+	}
+	if o.Branch != "" {
+		args = append(args, "--branch", o.Branch)
+	}
+	if o.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	if len(o.Sparse) > 0 {
+		args = append(args, "--sparse")
+	}
+	if o.Progress {
+		args = append(args, "--progress")
+	}
+	return args
+}
+
+// CloneRepository clones a repository into a new dated try and returns its
+// path. Git's progress output is written to output. Cancelling ctx kills git
+// and removes the partially cloned directory.
+func CloneRepository(ctx context.Context, source string, opts CloneOptions, output io.Writer) (string, error) {
+	gitURL, err := ParseGitURL(source)
+	if err != nil {
+		return "", err
+	}
+
+	if err := EnsureTryDirectory(); err != nil {
+		return "", fmt.Errorf("failed to ensure try directory: %w", err)
+	}
+
+	dirName := GenerateDatedName(gitURL.CloneName(opts.IncludeOwner))
+	fullPath := filepath.Join(GetTryPath(), dirName)
+
+	args := append([]string{"clone"}, opts.gitArgs()...)
+	if opts.UseCache && canMirror(gitURL) {
+		// A broken cache should never stop a clone, it only makes it slower
+		if mirror, err := EnsureMirror(ctx, gitURL, output); err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			fmt.Fprintf(output, "Warning: not using mirror cache: %v\n", err)
+		} else {
+			args = append(args, "--reference-if-able", mirror)
+			if GetConfig().Bool("cache.dissociate", true) {
+				args = append(args, "--dissociate")
+			}
+		}
+	}
+	args = append(args, gitURL.URL, fullPath)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Don't wait forever on helpers (git-remote-https, index-pack) that
+	// still hold the output pipe after git itself was killed
+	cmd.WaitDelay = 5 * time.Second
+
+	if err := cmd.Run(); err != nil {
+		// GenerateDatedName picked a fresh path, so anything there is ours
+		os.RemoveAll(fullPath)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	if len(opts.Sparse) > 0 {
+		sparseArgs := append([]string{"sparse-checkout", "set", "--cone"}, opts.Sparse...)
+		if _, err := runGit(fullPath, sparseArgs...); err != nil {
+			return fullPath, fmt.Errorf("failed to set up sparse checkout: %w", err)
+		}
+	}
+
+	if err := runCreateHooks(ctx, fullPath, output); err != nil {
+		return "", err
+	}
+
+	return fullPath, nil
+}
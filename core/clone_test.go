@@ -0,0 +1,122 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCloneArgs(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("clone.filter: blob:none\nclone.recurse-submodules: true\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantSource string
+		want       CloneOptions
+	}{
+		{
+			name:       "config defaults",
+			args:       []string{"gh:user/repo"},
+			wantSource: "gh:user/repo",
+			want:       CloneOptions{Filter: "blob:none", RecurseSubmodules: true},
+		},
+		{
+			name:       "flags after url",
+			args:       []string{"gh:user/repo", "--depth", "1", "--branch=dev", "--no-recurse-submodules"},
+			wantSource: "gh:user/repo",
+			want:       CloneOptions{Depth: 1, Filter: "blob:none", Branch: "dev"},
+		},
+		{
+			name:       "sparse paths",
+			args:       []string{"--sparse", "docs", "--sparse=tools/lint", "--owner", "gh:user/repo"},
+			wantSource: "gh:user/repo",
+			want:       CloneOptions{Filter: "blob:none", RecurseSubmodules: true, Sparse: []string{"docs", "tools/lint"}, IncludeOwner: true},
+		},
+		{
+			name:       "sparse path before url",
+			args:       []string{"--sparse", "docs", "gh:user/repo"},
+			wantSource: "gh:user/repo",
+			want:       CloneOptions{Filter: "blob:none", RecurseSubmodules: true, Sparse: []string{"docs"}},
+		},
+		{
+			name:       "sparse path list",
+			args:       []string{"gh:user/repo", "--sparse", "services/api, docs"},
+			wantSource: "gh:user/repo",
+			want:       CloneOptions{Filter: "blob:none", RecurseSubmodules: true, Sparse: []string{"services/api", "docs"}},
+		},
+		{
+			name:       "full overrides defaults",
+			args:       []string{"--depth=5", "--full", "-b", "main", "gh:user/repo"},
+			wantSource: "gh:user/repo",
+			want:       CloneOptions{Branch: "main", RecurseSubmodules: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, opts, err := ParseCloneArgs(tt.args)
+			if err != nil {
+				t.Fatalf("ParseCloneArgs(%v) returned error: %v", tt.args, err)
+			}
+			if source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+			if !reflect.DeepEqual(opts, tt.want) {
+				t.Errorf("options = %+v, want %+v", opts, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCloneArgsConfiguredSparse(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("clone.sparse: docs, tools\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"gh:user/repo"}, []string{"docs", "tools"}},
+		{[]string{"gh:user/repo", "--sparse", "src"}, []string{"src"}},
+		{[]string{"--sparse", "src", "--sparse=lib,web", "gh:user/repo"}, []string{"src", "lib", "web"}},
+		{[]string{"gh:user/repo", "--no-sparse"}, nil},
+		{[]string{"--no-sparse", "--sparse", "src", "gh:user/repo"}, []string{"src"}},
+		{[]string{"--sparse", "src", "--no-sparse", "gh:user/repo"}, nil},
+	}
+
+	for _, tt := range tests {
+		_, opts, err := ParseCloneArgs(tt.args)
+		if err != nil {
+			t.Errorf("ParseCloneArgs(%v) returned error: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(opts.Sparse, tt.want) {
+			t.Errorf("ParseCloneArgs(%v) sparse = %q, want %q", tt.args, opts.Sparse, tt.want)
+		}
+	}
+}
+
+func TestParseCloneArgsErrors(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	tests := [][]string{
+		{},
+		{"--depth"},
+		{"gh:user/repo", "--depth", "many"},
+		{"gh:user/repo", "--sparse"},
+		{"gh:user/repo", "--sparse", ","},
+		{"gh:user/repo", "--bogus"},
+		{"gh:user/repo", "gh:other/repo"},
+	}
+
+	for _, args := range tests {
+		if _, _, err := ParseCloneArgs(args); err == nil {
+			t.Errorf("ParseCloneArgs(%v) succeeded, want error", args)
+		}
+	}
+}
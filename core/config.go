@@ -0,0 +1,194 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Config holds settings read from the try config file.
+//
+// The file is a flat list of "key: value" lines. Blank lines and lines
+// starting with # are ignored, and related settings share a dotted prefix:
+//
+//	# ~/.config/try/config
+//	git.default-host: github.com
+//	git.alias.work: git.example.com
+//	clone.include-owner: true
+type Config struct {
+	values map[string]string
+}
+
+var (
+	configMu     sync.Mutex
+	configCache  *Config
+	configLoaded string
+)
+
+// ConfigDir returns the directory holding the config file, templates and
+// hooks: $XDG_CONFIG_HOME/try, falling back to ~/.config/try
+func ConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "try")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/tmp", "try")
+	}
+
+	return filepath.Join(home, ".config", "try")
+}
+
+// ConfigPath returns the config file location, overridable with TRY_CONFIG
+func ConfigPath() string {
+	if path := os.Getenv("TRY_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(ConfigDir(), "config")
+}
+
+// GetConfig returns the user's configuration. A missing file yields an
+// empty config; a malformed one is reported once and otherwise ignored.
+func GetConfig() *Config {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	path := ConfigPath()
+	if configCache != nil && configLoaded == path {
+		return configCache
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	configCache = config
+	configLoaded = path
+	return config
+}
+
+// LoadConfig reads a config file. It always returns a usable config, even
+// when an error is reported.
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{values: map[string]string{}}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return config, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return config, fmt.Errorf("%s:%d: expected \"key: value\"", path, lineNo)
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		config.values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return config, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return config, nil
+}
+
+// Get returns the raw value of a key and whether it was set
+func (c *Config) Get(key string) (string, bool) {
+	value, ok := c.values[strings.ToLower(key)]
+	return value, ok
+}
+
+// String returns the value of a key, or def when it is not set
+func (c *Config) String(key, def string) string {
+	if value, ok := c.Get(key); ok {
+		return value
+	}
+	return def
+}
+
+// Bool returns the value of a key as a boolean, or def when it is not set
+// or not a boolean
+func (c *Config) Bool(key string, def bool) bool {
+	value, ok := c.Get(key)
+	if !ok {
+		return def
+	}
+
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	case "false", "no", "off", "0":
+		return false
+	}
+	return def
+}
+
+// Int returns the value of a key as an integer, or def when it is not set
+// or not a number
+func (c *Config) Int(key string, def int) int {
+	value, ok := c.Get(key)
+	if !ok {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// List returns a comma separated value as a slice with empty items dropped
+func (c *Config) List(key string) []string {
+	value, ok := c.Get(key)
+	if !ok {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Section returns every key below prefix with the prefix removed, e.g.
+// Section("git.alias") turns "git.alias.work" into "work"
+func (c *Config) Section(prefix string) map[string]string {
+	prefix = strings.ToLower(prefix) + "."
+	section := map[string]string{}
+
+	for key, value := range c.values {
+		if strings.HasPrefix(key, prefix) {
+			section[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	return section
+}
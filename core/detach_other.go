@@ -0,0 +1,8 @@
+//go:build !unix
+
+package core
+
+import "os/exec"
+
+// detach is a no-op where processes don't inherit the terminal's session
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach moves cmd into a new session, away from try's terminal
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
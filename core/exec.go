@@ -0,0 +1,116 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ExecResult is the outcome of running a command in one try
+type ExecResult struct {
+	Dir      Directory
+	ExitCode int // -1 when the command couldn't be started
+	Err      error
+	Output   []byte // Combined stdout and stderr
+	Duration time.Duration
+}
+
+// TryCommand returns a command that runs in the try at dir, with TRY_DIR
+// and TRY_NAME set like for hooks
+func TryCommand(dir Directory, command []string) *exec.Cmd {
+	return tryCommandContext(context.Background(), dir, command)
+}
+
+// tryCommandContext is TryCommand for a command that is killed when ctx is
+// cancelled
+func tryCommandContext(ctx context.Context, dir Directory, command []string) *exec.Cmd {
+	path := dir.TargetPath()
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(),
+		"TRY_DIR="+path,
+		"TRY_NAME="+ExtractNameFromDirectory(filepath.Base(path)),
+	)
+	return cmd
+}
+
+// ExitCode returns the exit code for the error returned by running a
+// command: 0 for success, the command's code when it failed, and -1 when
+// it couldn't be run at all
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// ExecInDirectories runs command in every directory, at most jobs at a
+// time. Output is collected per try, and done is called with each result as
+// it finishes, one call at a time. Results are returned in the order of
+// directories.
+func ExecInDirectories(directories []Directory, command []string, jobs int, done func(ExecResult)) []ExecResult {
+	return execInDirectories(context.Background(), directories, command, nil, jobs, done)
+}
+
+// execInDirectories is ExecInDirectories with env added to every command's
+// environment. Cancelling ctx kills the running commands, and tries that
+// weren't started yet get ctx's error as their result.
+func execInDirectories(ctx context.Context, directories []Directory, command, env []string, jobs int, done func(ExecResult)) []ExecResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	results := make([]ExecResult, len(directories))
+	slots := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i, dir := range directories {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			var output bytes.Buffer
+			cmd := tryCommandContext(ctx, dir, command)
+			cmd.Env = append(cmd.Env, env...)
+			cmd.Stdout = &output
+			cmd.Stderr = &output
+			// Children such as ssh may hold on to the output after a kill
+			cmd.WaitDelay = time.Second
+
+			start := time.Now()
+			err := ctx.Err()
+			if err == nil {
+				err = cmd.Run()
+			}
+			result := ExecResult{
+				Dir:      dir,
+				ExitCode: ExitCode(err),
+				Err:      err,
+				Output:   output.Bytes(),
+				Duration: time.Since(start),
+			}
+			results[i] = result
+
+			if done != nil {
+				mu.Lock()
+				done(result)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
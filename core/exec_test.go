@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecInDirectories(t *testing.T) {
+	root := t.TempDir()
+	var dirs []Directory
+	for _, name := range []string{"2025-08-30-redis", "2025-08-30-api", "2025-08-30-broken"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(path, 0755)
+		dirs = append(dirs, Directory{Name: name, Path: path})
+	}
+
+	var reported atomic.Int32
+	command := []string{"sh", "-c", `echo "$TRY_NAME in $(basename "$PWD")"; [ "$TRY_NAME" != broken ] || exit 3`}
+	results := ExecInDirectories(dirs, command, 2, func(ExecResult) { reported.Add(1) })
+
+	if reported.Load() != 3 {
+		t.Errorf("done was called %d times, want 3", reported.Load())
+	}
+	for i, result := range results {
+		if result.Dir.Name != dirs[i].Name {
+			t.Errorf("result %d is for %s, want %s", i, result.Dir.Name, dirs[i].Name)
+		}
+		name := ExtractNameFromDirectory(dirs[i].Name)
+		if want := name + " in " + dirs[i].Name; strings.TrimSpace(string(result.Output)) != want {
+			t.Errorf("output for %s = %q, want %q", name, result.Output, want)
+		}
+	}
+
+	if results[0].ExitCode != 0 || results[2].ExitCode != 3 {
+		t.Errorf("exit codes = %d, %d; want 0, 3", results[0].ExitCode, results[2].ExitCode)
+	}
+
+	missing := ExecInDirectories(dirs[:1], []string{"try-no-such-command"}, 1, nil)
+	if missing[0].ExitCode != -1 || missing[0].Err == nil {
+		t.Errorf("missing command gave exit code %d, err %v; want -1 and an error", missing[0].ExitCode, missing[0].Err)
+	}
+}
+
+func TestExecInDirectoriesCancel(t *testing.T) {
+	root := t.TempDir()
+	var dirs []Directory
+	for _, name := range []string{"2025-08-30-slow", "2025-08-30-waiting"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(path, 0755)
+		dirs = append(dirs, Directory{Name: name, Path: path})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results := execInDirectories(ctx, dirs, []string{"sleep", "30"}, nil, 1, nil)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("cancelled commands ran for %v", elapsed)
+	}
+	if results[0].ExitCode == 0 {
+		t.Error("the running command wasn't stopped")
+	}
+	if !errors.Is(results[1].Err, context.DeadlineExceeded) || results[1].ExitCode != -1 {
+		t.Errorf("the waiting try got %d, %v; want -1 and the context's error", results[1].ExitCode, results[1].Err)
+	}
+}
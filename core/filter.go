@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Filter selects tries by their properties. It is written as space
+// separated terms that must all hold:
+//
+//	is:git        a git repository
+//	is:worktree   a git worktree
+//	is:promoted   an alias left behind by `try promote`
+//	is:dirty      a repository or worktree with uncommitted changes
+//	tag:wip       a try tagged wip in the selector
+//	redis         a name matching redis, like a search in the selector
+//
+// A leading - negates a term, e.g. "is:git -is:dirty".
+type Filter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	negate bool
+	is     string // Property after is:, empty for a name match
+	tag    string // Tag after tag:
+	word   string
+}
+
+// filterProperties are the values is: accepts
+var filterProperties = map[string]func(Directory) bool{
+	"git":      func(d Directory) bool { return d.IsGitRepo },
+	"worktree": func(d Directory) bool { return d.IsWorktree },
+	"promoted": func(d Directory) bool { return d.IsAlias },
+	"dirty":    isDirty,
+}
+
+// ParseFilter parses a filter expression. An empty one matches every try.
+func ParseFilter(expr string) (Filter, error) {
+	var filter Filter
+	for _, field := range strings.Fields(expr) {
+		term := filterTerm{}
+		if strings.HasPrefix(field, "-") && len(field) > 1 {
+			term.negate = true
+			field = field[1:]
+		}
+
+		if property, ok := strings.CutPrefix(field, "is:"); ok {
+			if _, known := filterProperties[property]; !known {
+				return Filter{}, fmt.Errorf("unknown filter is:%s (known: is:git, is:worktree, is:promoted, is:dirty)", property)
+			}
+			term.is = property
+		} else if tag, ok := strings.CutPrefix(field, "tag:"); ok && tag != "" {
+			term.tag = tag
+		} else {
+			term.word = field
+		}
+		filter.terms = append(filter.terms, term)
+	}
+	return filter, nil
+}
+
+// Match reports whether dir satisfies every term of the filter
+func (f Filter) Match(dir Directory) bool {
+	scorer := NewScorer()
+	for _, term := range f.terms {
+		var matched bool
+		switch {
+		case term.is != "":
+			matched = filterProperties[term.is](dir)
+		case term.tag != "":
+			matched = slices.Contains(dir.Tags, term.tag)
+		default:
+			matched = scorer.ScoreDirectory(dir.Name, term.word, dir.ModifiedTime).TextScore > 0
+		}
+		if matched == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterDirectories returns the directories matching filter
+func FilterDirectories(directories []Directory, filter Filter) []Directory {
+	var matched []Directory
+	for _, dir := range directories {
+		if filter.Match(dir) {
+			matched = append(matched, dir)
+		}
+	}
+	return matched
+}
+
+// isDirty reports whether a repository or worktree has uncommitted changes
+func isDirty(dir Directory) bool {
+	if !dir.IsGitRepo && !dir.IsWorktree {
+		return false
+	}
+	status, err := runGit(dir.TargetPath(), "status", "--porcelain")
+	return err == nil && status != ""
+}
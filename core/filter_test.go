@@ -0,0 +1,46 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	dirs := []Directory{
+		{Name: "2025-08-30-redis", IsGitRepo: true},
+		{Name: "2025-08-30-redis-worktree", IsWorktree: true},
+		{Name: "2025-08-30-notes", Tags: []string{"wip"}},
+		{Name: "2025-08-30-api", IsAlias: true},
+	}
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"", []string{"2025-08-30-redis", "2025-08-30-redis-worktree", "2025-08-30-notes", "2025-08-30-api"}},
+		{"is:git", []string{"2025-08-30-redis"}},
+		{"-is:git -is:worktree", []string{"2025-08-30-notes", "2025-08-30-api"}},
+		{"redis -is:git", []string{"2025-08-30-redis-worktree"}},
+		{"is:promoted", []string{"2025-08-30-api"}},
+		{"tag:wip", []string{"2025-08-30-notes"}},
+		{"-tag:wip -is:promoted", []string{"2025-08-30-redis", "2025-08-30-redis-worktree"}},
+	}
+
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q) failed: %v", tt.expr, err)
+		}
+		var got []string
+		for _, dir := range FilterDirectories(dirs, filter) {
+			got = append(got, dir.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("filter %q matched %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+
+	if _, err := ParseFilter("is:shiny"); err == nil {
+		t.Error("ParseFilter(is:shiny) succeeded, want error")
+	}
+}
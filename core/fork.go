@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ForkOptions controls how ForkDirectory copies a try
+type ForkOptions struct {
+	Name          string // Name of the fork, default the original's name
+	SkipArtifacts bool   // Leave out build output and dependencies, see ArtifactDirs
+	Worktree      bool   // Make a worktree on a new branch from HEAD instead of copying
+}
+
+// defaultArtifactDirs are directories that can be rebuilt or reinstalled
+var defaultArtifactDirs = []string{
+	"node_modules", ".next", ".nuxt", ".turbo", ".parcel-cache", "dist", "build",
+	"target", ".gradle", "__pycache__", ".venv", "venv", ".tox",
+	".pytest_cache", ".mypy_cache", ".zig-cache", "zig-out",
+}
+
+// ArtifactDirs returns the directory names a fork with SkipArtifacts leaves
+// out, wherever they are in the try: the fork.artifacts config key, or
+// common build output and dependency folders
+func ArtifactDirs() []string {
+	if dirs := GetConfig().List("fork.artifacts"); len(dirs) > 0 {
+		return dirs
+	}
+	return defaultArtifactDirs
+}
+
+// ForkDirectory copies a try into a new dated try and returns its path.
+// Files are cloned copy-on-write where the filesystem supports it, so even
+// large tries fork quickly and take no extra space until they diverge.
+// With opts.Worktree the fork is a worktree on a new branch named after it,
+// starting from the try's HEAD; uncommitted changes stay behind.
+func ForkDirectory(dir Directory, opts ForkOptions, output io.Writer) (string, error) {
+	name := opts.Name
+	if name == "" {
+		name = ExtractNameFromDirectory(dir.Name)
+	}
+	src := dir.TargetPath()
+
+	if opts.Worktree {
+		if !dir.IsGitRepo && !dir.IsWorktree {
+			return "", fmt.Errorf("%s is not a git repository", dir.Name)
+		}
+		if isDirty(dir) {
+			fmt.Fprintf(output, "Warning: uncommitted changes in %s are not part of the fork\n", dir.Name)
+		}
+		branch := uniqueBranch(src, normalizeName(name))
+		return CreateWorktree(src, name, branch)
+	}
+
+	// A copied worktree would claim the same place in its repository
+	if dir.IsWorktree {
+		return "", fmt.Errorf("%s is a worktree, fork it as a new worktree instead", dir.Name)
+	}
+
+	if err := EnsureTryDirectory(); err != nil {
+		return "", fmt.Errorf("failed to ensure try directory: %w", err)
+	}
+	dest := filepath.Join(GetTryPath(), GenerateDatedName(name))
+
+	// The original's worktrees belong to it, a copy of their administrative
+	// files would make the fork claim them too
+	skip := map[string]bool{filepath.Join(".git", "worktrees"): true}
+	if opts.SkipArtifacts {
+		for _, name := range ArtifactDirs() {
+			skip[name] = true
+		}
+	}
+
+	if err := copyTree(src, dest, skip); err != nil {
+		os.RemoveAll(dest)
+		return "", fmt.Errorf("failed to copy %s: %w", dir.Name, err)
+	}
+
+	if err := runCreateHooks(context.Background(), dest, output); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// uniqueBranch returns name, or name-1, name-2, ... when the repository at
+// repoPath already has a branch of that name
+func uniqueBranch(repoPath, name string) string {
+	candidate := name
+	for counter := 1; ; counter++ {
+		if _, err := runGit(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+candidate); err != nil {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, counter)
+	}
+}
+
+// copyTree copies the directory src to dst, which must not exist, keeping
+// permissions, modification times and symlinks. Directories whose name, or
+// path relative to src, is in skip are left out.
+func copyTree(src, dst string, skip map[string]bool) error {
+	type copiedDir struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []copiedDir
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != src && (skip[d.Name()] || skip[rel]) {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			// Stay writable so the directory's contents can be copied in,
+			// the real permissions are restored once it is filled
+			dirs = append(dirs, copiedDir{target, info})
+			return os.Mkdir(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return cloneFile(path, target, info)
+		}
+		// Sockets, pipes and devices don't belong in a copy
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Children come after their parents, so going backwards finishes every
+	// directory before the one holding it, whose time would change otherwise.
+	// dst itself keeps the time of the copy, so a fork shows up as new.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+		if err := os.Chmod(dir.path, dir.info.Mode().Perm()); err != nil {
+			return err
+		}
+		if dir.path == dst {
+			continue
+		}
+		if err := os.Chtimes(dir.path, dir.info.ModTime(), dir.info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneFile copies a regular file, as a copy-on-write clone if possible
+func cloneFile(src, dst string, info fs.FileInfo) error {
+	if err := reflink(src, dst, info.Mode().Perm()); err != nil {
+		if err := copyFile(src, dst); err != nil {
+			return err
+		}
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestForkDirectoryCopies(t *testing.T) {
+	root := t.TempDir()
+	tries := filepath.Join(root, "tries")
+	t.Setenv("TRY_PATH", tries)
+	t.Setenv("TRY_CONFIG", filepath.Join(root, "config"))
+	t.Setenv("TRY_SKIP_HOOKS", "1")
+
+	src := filepath.Join(tries, "2025-08-30-web")
+	os.MkdirAll(filepath.Join(src, "node_modules", "react"), 0755)
+	os.MkdirAll(filepath.Join(src, "packages", "ui", "node_modules"), 0755)
+	os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.Symlink("run.sh", filepath.Join(src, "start"))
+	old := time.Date(2025, 8, 30, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(filepath.Join(src, "run.sh"), old, old)
+	os.MkdirAll(filepath.Join(src, "docs"), 0755)
+	os.WriteFile(filepath.Join(src, "docs", "README"), nil, 0644)
+	os.Chmod(filepath.Join(src, "docs"), 0555)
+	os.Chtimes(filepath.Join(src, "docs"), old, old)
+	os.MkdirAll(filepath.Join(src, ".git", "worktrees", "web-feature"), 0755)
+	os.MkdirAll(filepath.Join(src, ".git", "objects"), 0755)
+	dir := Directory{Name: "2025-08-30-web", Path: src}
+
+	fork, err := ForkDirectory(dir, ForkOptions{}, io.Discard)
+	if err != nil {
+		t.Fatalf("ForkDirectory failed: %v", err)
+	}
+	if want := time.Now().Format("2006-01-02") + "-web"; filepath.Base(fork) != want {
+		t.Errorf("fork named %s, want %s", filepath.Base(fork), want)
+	}
+
+	info, err := os.Stat(filepath.Join(fork, "run.sh"))
+	if err != nil || info.Mode().Perm() != 0755 || !info.ModTime().Equal(old) {
+		t.Errorf("run.sh copied as %v, %v", info, err)
+	}
+	if link, _ := os.Readlink(filepath.Join(fork, "start")); link != "run.sh" {
+		t.Errorf("start links to %q, want run.sh", link)
+	}
+	if _, err := os.Stat(filepath.Join(fork, "node_modules", "react")); err != nil {
+		t.Errorf("plain fork left out node_modules: %v", err)
+	}
+	// Let the temporary directory be removed
+	t.Cleanup(func() {
+		docs, _ := filepath.Glob(filepath.Join(tries, "*", "docs"))
+		for _, path := range docs {
+			os.Chmod(path, 0755)
+		}
+	})
+	info, err = os.Stat(filepath.Join(fork, "docs"))
+	if err != nil || info.Mode().Perm() != 0555 || !info.ModTime().Equal(old) {
+		t.Errorf("docs copied as %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(fork, ".git", "objects")); err != nil {
+		t.Errorf(".git/objects is missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(fork, ".git", "worktrees")); !os.IsNotExist(err) {
+		t.Error("the original's worktrees were copied into the fork")
+	}
+
+	lean, err := ForkDirectory(dir, ForkOptions{Name: "web lean", SkipArtifacts: true}, io.Discard)
+	if err != nil {
+		t.Fatalf("ForkDirectory with SkipArtifacts failed: %v", err)
+	}
+	for _, artifact := range []string{"node_modules", "packages/ui/node_modules"} {
+		if _, err := os.Stat(filepath.Join(lean, artifact)); !os.IsNotExist(err) {
+			t.Errorf("%s was copied despite SkipArtifacts", artifact)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(lean, "packages", "ui")); err != nil {
+		t.Errorf("packages/ui is missing: %v", err)
+	}
+}
+
+func TestForkDirectoryWorktree(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	dir := Directory{Name: "2025-08-30-repo", Path: repo, IsGitRepo: true}
+
+	fork, err := ForkDirectory(dir, ForkOptions{Name: "repo-retry", Worktree: true}, io.Discard)
+	if err != nil {
+		t.Fatalf("ForkDirectory failed: %v", err)
+	}
+	if branch := gitCommand(t, fork, "branch", "--show-current"); branch != "repo-retry" {
+		t.Errorf("fork is on branch %q, want repo-retry", branch)
+	}
+	if head, want := gitCommand(t, fork, "rev-parse", "HEAD"), gitCommand(t, repo, "rev-parse", "HEAD"); head != want {
+		t.Errorf("fork starts at %s, want HEAD %s", head, want)
+	}
+
+	// Forking again, e.g. on a later day, picks a free branch name
+	gitCommand(t, repo, "branch", "repo-retry-1")
+	again, err := ForkDirectory(dir, ForkOptions{Name: "repo-retry", Worktree: true}, io.Discard)
+	if err != nil {
+		t.Fatalf("second ForkDirectory failed: %v", err)
+	}
+	if branch := gitCommand(t, again, "branch", "--show-current"); branch != "repo-retry-2" {
+		t.Errorf("second fork is on branch %q, want repo-retry-2", branch)
+	}
+
+	// Copying the worktree would break it, so that is refused
+	worktree := Directory{Name: filepath.Base(fork), Path: fork, IsWorktree: true}
+	if _, err := ForkDirectory(worktree, ForkOptions{}, io.Discard); err == nil {
+		t.Error("copying a worktree succeeded, want error")
+	}
+	if _, err := ForkDirectory(Directory{Name: "plain", Path: root}, ForkOptions{Worktree: true}, io.Discard); err == nil {
+		t.Error("worktree fork of a plain directory succeeded, want error")
+	}
+}
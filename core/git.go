@@ -0,0 +1,265 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w\nOutput: %s", strings.Join(args, " "), err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// worktreeAdminDir returns the administrative directory of a worktree,
+// i.e. the <repo>/.git/worktrees/<name> directory its .git file points to
+func worktreeAdminDir(worktreePath string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(worktreePath, ".git"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read .git file: %w", err)
+	}
+
+	gitdirLine := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(gitdirLine, "gitdir:") {
+		return "", fmt.Errorf("invalid .git file format")
+	}
+
+	gitdir := strings.TrimSpace(strings.TrimPrefix(gitdirLine, "gitdir:"))
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(worktreePath, gitdir)
+	}
+
+	return filepath.Clean(gitdir), nil
+}
+
+// worktreeCommonDir returns the git directory shared by a worktree and its
+// parent repository
+func worktreeCommonDir(adminDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(adminDir, "commondir"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read commondir: %w", err)
+	}
+
+	commonDir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(adminDir, commonDir)
+	}
+
+	return filepath.Clean(commonDir), nil
+}
+
+// CurrentBranch returns the branch checked out in a repository or worktree,
+// the abbreviated commit when HEAD is detached, or "" for other directories.
+// It reads HEAD directly, which is quick enough to do for every try.
+func CurrentBranch(dir Directory) string {
+	gitDir := filepath.Join(dir.Path, ".git")
+	if dir.IsWorktree {
+		adminDir, err := worktreeAdminDir(dir.Path)
+		if err != nil {
+			return ""
+		}
+		gitDir = adminDir
+	} else if !dir.IsGitRepo {
+		return ""
+	}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	head := strings.TrimSpace(string(content))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head
+}
+
+// DetachWorktree converts a worktree into a standalone repository. The
+// parent's objects, branches and remotes are copied into a new .git
+// directory, the worktree's HEAD and index are carried over so the current
+// branch and any uncommitted changes survive, and the worktree is finally
+// unregistered from the parent.
+func DetachWorktree(path string) error {
+	if !isWorktree(path) {
+		return fmt.Errorf("%s is not a git worktree", path)
+	}
+
+	adminDir, err := worktreeAdminDir(path)
+	if err != nil {
+		return err
+	}
+
+	// git keeps a locked worktree registered even when it looks gone, so
+	// leave it to the user to decide whether it may go
+	if _, err := os.Stat(filepath.Join(adminDir, "locked")); err == nil {
+		return fmt.Errorf("%s is locked, unlock it with git worktree unlock first", path)
+	}
+
+	commonDir, err := worktreeCommonDir(adminDir)
+	if err != nil {
+		return err
+	}
+
+	head, err := os.ReadFile(filepath.Join(adminDir, "HEAD"))
+	if err != nil {
+		return fmt.Errorf("failed to read worktree HEAD: %w", err)
+	}
+
+	// Build the new git directory next to the worktree and only swap it in
+	// once everything has been copied
+	newGitDir := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".detach.git")
+	os.RemoveAll(newGitDir)
+
+	if _, err := runGit(path, "clone", "--bare", "--no-hardlinks", "--quiet", commonDir, newGitDir); err != nil {
+		return fmt.Errorf("failed to copy repository: %w", err)
+	}
+
+	if err := copyRepositorySettings(commonDir, newGitDir); err != nil {
+		os.RemoveAll(newGitDir)
+		return err
+	}
+
+	if err := copyFile(filepath.Join(adminDir, "index"), filepath.Join(newGitDir, "index")); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(newGitDir)
+		return fmt.Errorf("failed to copy index: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(newGitDir, "HEAD"), head, 0644); err != nil {
+		os.RemoveAll(newGitDir)
+		return fmt.Errorf("failed to write HEAD: %w", err)
+	}
+
+	gitFile := filepath.Join(path, ".git")
+	gitFileContent, err := os.ReadFile(gitFile)
+	if err != nil {
+		os.RemoveAll(newGitDir)
+		return fmt.Errorf("failed to read .git file: %w", err)
+	}
+
+	if err := os.Remove(gitFile); err != nil {
+		os.RemoveAll(newGitDir)
+		return fmt.Errorf("failed to remove .git file: %w", err)
+	}
+
+	if err := os.Rename(newGitDir, gitFile); err != nil {
+		// Put the worktree back the way we found it
+		os.WriteFile(gitFile, gitFileContent, 0644)
+		return fmt.Errorf("failed to install new .git directory, it was left at %s: %w", newGitDir, err)
+	}
+
+	// Unregister only this worktree; pruning would also drop every other
+	// worktree of the parent that happens to be missing right now
+	if err := os.RemoveAll(adminDir); err != nil {
+		return fmt.Errorf("failed to unregister worktree, git worktree prune in the parent cleans it up: %w", err)
+	}
+
+	return nil
+}
+
+// copyRepositorySettings makes a bare clone of a local repository look like
+// an ordinary checkout of the original: the remote pointing back at the
+// local copy is replaced by the original's remotes and branch tracking
+// configuration, and the original's remote-tracking branches are fetched
+func copyRepositorySettings(sourceGitDir, gitDir string) error {
+	if _, err := runGit(gitDir, "--git-dir", gitDir, "config", "core.bare", "false"); err != nil {
+		return fmt.Errorf("failed to configure repository: %w", err)
+	}
+
+	if _, err := runGit(gitDir, "--git-dir", gitDir, "remote", "remove", "origin"); err != nil {
+		return fmt.Errorf("failed to remove temporary remote: %w", err)
+	}
+
+	// --get-regexp exits with status 1 when nothing matches, which just
+	// means there is no configuration to copy
+	settings, _ := runGit(sourceGitDir, "--git-dir", sourceGitDir, "config", "--local", "--get-regexp", `^(remote|branch)\.`)
+	for _, line := range strings.Split(settings, "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if _, err := runGit(gitDir, "--git-dir", gitDir, "config", "--add", key, value); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", key, err)
+		}
+	}
+
+	if _, err := runGit(gitDir, "--git-dir", gitDir, "fetch", "--quiet", "--no-tags", sourceGitDir, "+refs/remotes/*:refs/remotes/*"); err != nil {
+		return fmt.Errorf("failed to copy remote-tracking branches: %w", err)
+	}
+
+	return nil
+}
+
+// copyFile copies a regular file, preserving its permission bits
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// CreateWorktree adds a worktree of the repository at repoPath as a new
+// dated try and returns its path. The worktree gets a new branch when branch
+// is set and a detached HEAD otherwise.
+func CreateWorktree(repoPath, name, branch string) (string, error) {
+	if name == "" {
+		name = filepath.Base(repoPath) + "-worktree"
+	}
+
+	if err := EnsureTryDirectory(); err != nil {
+		return "", fmt.Errorf("failed to ensure try directory: %w", err)
+	}
+
+	fullPath := filepath.Join(GetTryPath(), GenerateDatedName(name))
+
+	args := []string{"worktree", "add"}
+	if branch != "" {
+		args = append(args, "-b", branch)
+	} else {
+		args = append(args, "--detach")
+	}
+	args = append(args, fullPath)
+
+	if _, err := runGit(repoPath, args...); err != nil {
+		return "", fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	if err := runCreateHooks(context.Background(), fullPath, HookOutput); err != nil {
+		return "", err
+	}
+
+	return fullPath, nil
+}
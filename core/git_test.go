@@ -0,0 +1,92 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCurrentBranch(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	want := gitCommand(t, repo, "branch", "--show-current")
+	if got := CurrentBranch(Directory{Path: repo, IsGitRepo: true}); got != want {
+		t.Errorf("CurrentBranch(repo) = %q, want %q", got, want)
+	}
+
+	worktree := filepath.Join(tries, "2025-08-30-repo-feature")
+	gitCommand(t, repo, "worktree", "add", "--quiet", "-b", "feature/x", worktree)
+	if got := CurrentBranch(Directory{Path: worktree, IsWorktree: true}); got != "feature/x" {
+		t.Errorf("CurrentBranch(worktree) = %q, want feature/x", got)
+	}
+
+	gitCommand(t, worktree, "checkout", "--quiet", "--detach")
+	head := gitCommand(t, worktree, "rev-parse", "--short=7", "HEAD")
+	if got := CurrentBranch(Directory{Path: worktree, IsWorktree: true}); got != head {
+		t.Errorf("CurrentBranch(detached) = %q, want %q", got, head)
+	}
+
+	if got := CurrentBranch(Directory{Path: root}); got != "" {
+		t.Errorf("CurrentBranch(plain directory) = %q, want empty", got)
+	}
+}
+
+func TestDetachWorktree(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	worktree := filepath.Join(tries, "2025-08-30-repo-feature")
+	gitCommand(t, repo, "worktree", "add", "--quiet", "-b", "feature", worktree)
+	os.WriteFile(filepath.Join(worktree, "staged.txt"), []byte("staged\n"), 0644)
+	gitCommand(t, worktree, "add", "staged.txt")
+
+	// A worktree whose directory is gone for now must stay registered
+	missing := filepath.Join(tries, "2025-08-30-repo-missing")
+	gitCommand(t, repo, "worktree", "add", "--quiet", "-b", "missing", missing)
+	os.RemoveAll(missing)
+
+	gitCommand(t, repo, "worktree", "lock", worktree)
+	if err := DetachWorktree(worktree); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("detaching a locked worktree: error %v, want it refused", err)
+	}
+	if info, err := os.Stat(filepath.Join(worktree, ".git")); err != nil || info.IsDir() {
+		t.Fatalf("refused detach changed .git: %v", err)
+	}
+	gitCommand(t, repo, "worktree", "unlock", worktree)
+
+	if err := DetachWorktree(worktree); err != nil {
+		t.Fatalf("DetachWorktree failed: %v", err)
+	}
+
+	if info, err := os.Stat(filepath.Join(worktree, ".git")); err != nil || !info.IsDir() {
+		t.Fatalf(".git is not a directory after detaching: %v", err)
+	}
+	if branch := gitCommand(t, worktree, "branch", "--show-current"); branch != "feature" {
+		t.Errorf("detached repository is on branch %q, want feature", branch)
+	}
+	if status := gitCommand(t, worktree, "status", "--porcelain"); status != "A  staged.txt" {
+		t.Errorf("git status = %q, want the staged file kept in the index", status)
+	}
+	if url := gitCommand(t, worktree, "remote", "get-url", "origin"); url != source {
+		t.Errorf("origin = %q, want %q", url, source)
+	}
+	if gitCommand(t, worktree, "rev-parse", "origin/HEAD") != gitCommand(t, repo, "rev-parse", "origin/HEAD") {
+		t.Error("remote-tracking branches were not copied")
+	}
+
+	if list := gitCommand(t, repo, "worktree", "list", "--porcelain"); strings.Contains(list, worktree) {
+		t.Errorf("parent still lists the worktree: %q", list)
+	}
+	if list := gitCommand(t, repo, "worktree", "list", "--porcelain"); !strings.Contains(list, missing) {
+		t.Errorf("parent no longer lists the missing worktree: %q", list)
+	}
+	if err := DetachWorktree(repo); err == nil {
+		t.Error("detaching a repository that isn't a worktree succeeded, want error")
+	}
+}
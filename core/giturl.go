@@ -0,0 +1,169 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GitURL is a clone source resolved from a URL, shorthand or local path
+type GitURL struct {
+	URL   string // What gets passed to git clone
+	Host  string // Empty for local repositories
+	Owner string // User, organization or group path, e.g. "group/sub"
+	Name  string // Repository name without .git
+}
+
+// Host aliases available without any configuration. More can be added, or
+// these overridden, with "git.alias.<name>: <host>" in the config file.
+var builtinHostAliases = map[string]string{
+	"gh": "github.com",
+	"gl": "gitlab.com",
+	"bb": "bitbucket.org",
+}
+
+var (
+	scpLikePattern   = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):(.+)$`)
+	shorthandPattern = regexp.MustCompile(`^[\w.-]+(?:/[\w.-]+)+$`)
+)
+
+// ParseGitURL resolves anything `try clone` accepts into a clone source:
+//
+//	https://github.com/user/repo.git  http, https, ssh, git and file URLs
+//	git@github.com:user/repo.git      scp-like syntax
+//	gh:user/repo, gl:group/sub/repo    host aliases
+//	user/repo                          repository on git.default-host
+//	../repo.git, /srv/git/repo.git     local repositories
+func ParseGitURL(input string) (GitURL, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return GitURL{}, fmt.Errorf("empty git URL")
+	}
+
+	if strings.Contains(input, "://") {
+		u, err := url.Parse(input)
+		if err != nil {
+			return GitURL{}, fmt.Errorf("invalid git URL %q: %w", input, err)
+		}
+
+		switch u.Scheme {
+		case "http", "https", "ssh", "git", "git+ssh", "ssh+git":
+			return newGitURL(input, u.Hostname(), u.Path), nil
+		case "file":
+			return newLocalGitURL(input, u.Path), nil
+		}
+		return GitURL{}, fmt.Errorf("unsupported git URL scheme %q", u.Scheme)
+	}
+
+	if isLocalRepositoryPath(input) {
+		path, err := filepath.Abs(ExpandHome(input))
+		if err != nil {
+			return GitURL{}, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		return newLocalGitURL(path, path), nil
+	}
+
+	if alias, path, ok := strings.Cut(input, ":"); ok {
+		if host, ok := lookupHostAlias(alias); ok {
+			return newRemoteGitURL(host, path), nil
+		}
+	}
+
+	if match := scpLikePattern.FindStringSubmatch(input); match != nil {
+		return newGitURL(input, match[1], match[2]), nil
+	}
+
+	if shorthandPattern.MatchString(input) {
+		host := GetConfig().String("git.default-host", "github.com")
+		return newRemoteGitURL(host, input), nil
+	}
+
+	return GitURL{}, fmt.Errorf("%q is not a git URL", input)
+}
+
+// IsGitURL reports whether an argument is unambiguously a clone source, so
+// `try <arg>` can clone instead of searching. The user/repo shorthand is
+// deliberately not recognized here since it looks like a search query.
+func IsGitURL(input string) bool {
+	for _, prefix := range []string{"http://", "https://", "ssh://", "git://", "file://", "git@"} {
+		if strings.HasPrefix(input, prefix) {
+			return true
+		}
+	}
+
+	if strings.HasSuffix(input, ".git") {
+		return true
+	}
+
+	if alias, path, ok := strings.Cut(input, ":"); ok && path != "" {
+		_, ok := lookupHostAlias(alias)
+		return ok
+	}
+
+	return false
+}
+
+// CloneName returns the name to give a clone, optionally prefixed with the
+// owner (user/repo becomes user-repo) to avoid collisions between forks
+func (u GitURL) CloneName(includeOwner bool) string {
+	if includeOwner && u.Owner != "" {
+		return strings.ReplaceAll(u.Owner, "/", "-") + "-" + u.Name
+	}
+	return u.Name
+}
+
+func ExtractNameFromGitURL(url string) string {
+	parsed, err := ParseGitURL(url)
+	if err != nil || parsed.Name == "" {
+		return "repo"
+	}
+	return parsed.Name
+}
+
+func newGitURL(cloneURL, host, path string) GitURL {
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	owner, name := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		owner, name = path[:i], path[i+1:]
+	}
+
+	return GitURL{URL: cloneURL, Host: host, Owner: owner, Name: name}
+}
+
+func newRemoteGitURL(host, path string) GitURL {
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	cloneURL := fmt.Sprintf("https://%s/%s.git", host, path)
+	if GetConfig().String("git.protocol", "https") == "ssh" {
+		cloneURL = fmt.Sprintf("git@%s:%s.git", host, path)
+	}
+
+	return newGitURL(cloneURL, host, path)
+}
+
+func newLocalGitURL(cloneURL, path string) GitURL {
+	name := strings.TrimSuffix(filepath.Base(filepath.Clean(path)), ".git")
+	return GitURL{URL: cloneURL, Name: name}
+}
+
+func lookupHostAlias(alias string) (string, bool) {
+	if host, ok := GetConfig().Section("git.alias")[strings.ToLower(alias)]; ok {
+		return host, true
+	}
+	host, ok := builtinHostAliases[strings.ToLower(alias)]
+	return host, ok
+}
+
+func isLocalRepositoryPath(input string) bool {
+	if filepath.IsAbs(input) || input == "." || input == ".." || input == "~" ||
+		strings.HasPrefix(input, "./") || strings.HasPrefix(input, "../") || strings.HasPrefix(input, "~/") {
+		return true
+	}
+
+	info, err := os.Stat(input)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitURL(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	os.WriteFile(config, []byte("git.alias.work: git.example.com\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+
+	bare := filepath.Join(dir, "local.git")
+	os.Mkdir(bare, 0755)
+
+	tests := []struct {
+		name      string
+		input     string
+		wantURL   string
+		wantHost  string
+		wantOwner string
+		wantName  string
+	}{
+		{"https", "https://github.com/user/repo.git", "https://github.com/user/repo.git", "github.com", "user", "repo"},
+		{"https without .git", "https://github.com/user/repo", "https://github.com/user/repo", "github.com", "user", "repo"},
+		{"https trailing slash", "https://github.com/user/repo/", "https://github.com/user/repo/", "github.com", "user", "repo"},
+		{"http", "http://git.local/team/tool.git", "http://git.local/team/tool.git", "git.local", "team", "tool"},
+		{"nested groups", "https://gitlab.com/group/sub/repo.git", "https://gitlab.com/group/sub/repo.git", "gitlab.com", "group/sub", "repo"},
+		{"scp-like", "git@github.com:user/repo.git", "git@github.com:user/repo.git", "github.com", "user", "repo"},
+		{"scp-like without user", "github.com:user/repo", "github.com:user/repo", "github.com", "user", "repo"},
+		{"ssh", "ssh://git@github.com/user/repo.git", "ssh://git@github.com/user/repo.git", "github.com", "user", "repo"},
+		{"ssh with port", "ssh://git@git.example.com:2222/user/repo.git", "ssh://git@git.example.com:2222/user/repo.git", "git.example.com", "user", "repo"},
+		{"git protocol", "git://git.kernel.org/pub/scm/git/git.git", "git://git.kernel.org/pub/scm/git/git.git", "git.kernel.org", "pub/scm/git", "git"},
+		{"file", "file:///srv/git/project.git", "file:///srv/git/project.git", "", "", "project"},
+		{"github alias", "gh:user/repo", "https://github.com/user/repo.git", "github.com", "user", "repo"},
+		{"gitlab alias", "gl:group/sub/repo", "https://gitlab.com/group/sub/repo.git", "gitlab.com", "group/sub", "repo"},
+		{"bitbucket alias", "bb:team/repo.git", "https://bitbucket.org/team/repo.git", "bitbucket.org", "team", "repo"},
+		{"configured alias", "work:infra/deploy", "https://git.example.com/infra/deploy.git", "git.example.com", "infra", "deploy"},
+		{"owner shorthand", "user/repo", "https://github.com/user/repo.git", "github.com", "user", "repo"},
+		{"local bare repo", bare, bare, "", "", "local"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGitURL(tt.input)
+			if err != nil {
+				t.Fatalf("ParseGitURL(%q) returned error: %v", tt.input, err)
+			}
+			if got.URL != tt.wantURL || got.Host != tt.wantHost || got.Owner != tt.wantOwner || got.Name != tt.wantName {
+				t.Errorf("ParseGitURL(%q) = %+v, want URL=%q Host=%q Owner=%q Name=%q",
+					tt.input, got, tt.wantURL, tt.wantHost, tt.wantOwner, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseGitURLConfiguredDefaults(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("git.default-host: gitlab.com\ngit.protocol: ssh\ngit.alias.gh: github.example.com\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+
+	tests := []struct {
+		input   string
+		wantURL string
+	}{
+		{"user/repo", "git@gitlab.com:user/repo.git"},
+		{"gh:user/repo", "git@github.example.com:user/repo.git"},
+		{"gl:group/repo", "git@gitlab.com:group/repo.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseGitURL(tt.input)
+			if err != nil {
+				t.Fatalf("ParseGitURL(%q) returned error: %v", tt.input, err)
+			}
+			if got.URL != tt.wantURL {
+				t.Errorf("ParseGitURL(%q).URL = %q, want %q", tt.input, got.URL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestParseGitURLErrors(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	for _, input := range []string{"", "redis", "ftp://example.com/repo.git", "my project/notes"} {
+		t.Run(input, func(t *testing.T) {
+			if got, err := ParseGitURL(input); err == nil {
+				t.Errorf("ParseGitURL(%q) = %+v, want error", input, got)
+			}
+		})
+	}
+}
+
+func TestIsGitURL(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"https://github.com/user/repo", true},
+		{"http://example.com/repo", true},
+		{"ssh://git@host/repo.git", true},
+		{"git://host/repo.git", true},
+		{"file:///srv/repo.git", true},
+		{"git@github.com:user/repo.git", true},
+		{"repo.git", true},
+		{"gh:user/repo", true},
+		{"gl:group/sub/repo", true},
+		{"gh:", false},
+		{"user/repo", false},
+		{"redis", false},
+		{"my experiment", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsGitURL(tt.input); got != tt.want {
+				t.Errorf("IsGitURL(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneName(t *testing.T) {
+	tests := []struct {
+		url          GitURL
+		includeOwner bool
+		want         string
+	}{
+		{GitURL{Owner: "user", Name: "repo"}, false, "repo"},
+		{GitURL{Owner: "user", Name: "repo"}, true, "user-repo"},
+		{GitURL{Owner: "group/sub", Name: "repo"}, true, "group-sub-repo"},
+		{GitURL{Name: "local"}, true, "local"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.url.CloneName(tt.includeOwner); got != tt.want {
+				t.Errorf("CloneName(%v) = %q, want %q", tt.includeOwner, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Hook events
+const (
+	HookOnCreate = "on-create" // A try was created, cloned or added as a worktree
+	HookOnEnter  = "on-enter"  // A try was picked in the selector
+	HookOnDelete = "on-delete" // A try is about to be deleted
+)
+
+// defaultHookTimeout applies when hooks.timeout isn't configured
+const defaultHookTimeout = 30 * time.Second
+
+// HookOutput receives the output of hooks fired by functions that don't take
+// a writer of their own. The selector redirects it while it owns the terminal.
+var HookOutput io.Writer = os.Stderr
+
+// HooksDir returns the directory holding global hooks,
+// ~/.config/try/hooks by default
+func HooksDir() string {
+	return filepath.Join(ConfigDir(), "hooks")
+}
+
+// HookError reports a hook that failed and thereby aborted its event
+type HookError struct {
+	Event string
+	Hook  string
+	Err   error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook %s failed: %v", e.Event, e.Hook, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// FindHooks returns the executables to run for event on the try at path:
+// the global hooks first, then the try's own .try/hooks if they have been
+// allowed with AllowTryHooks. A hook is either an executable named after the
+// event or a directory of that name whose executables run in lexical order.
+func FindHooks(event, path string) []string {
+	hooks := hooksIn(filepath.Join(HooksDir(), event))
+	if tryHooksAllowed(path) {
+		hooks = append(hooks, hooksIn(filepath.Join(tryHooksDir(path), event))...)
+	}
+	return hooks
+}
+
+// tryHooksDir is where a try keeps its own hooks
+func tryHooksDir(path string) string {
+	return filepath.Join(path, ".try", "hooks")
+}
+
+// hooksFingerprint hashes the names, modes and contents of a try's hooks,
+// or returns "" when it has none
+func hooksFingerprint(path string) (string, error) {
+	dir := tryHooksDir(path)
+	if _, err := os.Stat(dir); err != nil {
+		return "", nil
+	}
+
+	hash := sha256.New()
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s %s\n", filepath.ToSlash(rel), info.Mode())
+		if info.Mode().IsRegular() {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			hash.Write(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read hooks: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// tryHooksAllowed reports whether the try's hooks are exactly the ones that
+// were allowed. A clone, fork or worktree brings hooks nobody has reviewed,
+// and a pull may change reviewed ones, so neither runs until allowed.
+func tryHooksAllowed(path string) bool {
+	state, err := LoadState()
+	if err != nil {
+		return false
+	}
+	try, ok := state.Tries[path]
+	if !ok || try.AllowedHooks == "" {
+		return false
+	}
+	fingerprint, err := hooksFingerprint(path)
+	return err == nil && fingerprint == try.AllowedHooks
+}
+
+// AllowTryHooks lets the hooks in the try's .try/hooks run, as they are now
+func AllowTryHooks(path string) error {
+	fingerprint, err := hooksFingerprint(path)
+	if err != nil {
+		return err
+	}
+	if fingerprint == "" {
+		return fmt.Errorf("%s has no hooks in .try/hooks", filepath.Base(path))
+	}
+	return UpdateState(func(state *State) {
+		state.Try(path).AllowedHooks = fingerprint
+	})
+}
+
+// DenyTryHooks stops the hooks in the try's .try/hooks from running
+func DenyTryHooks(path string) error {
+	return UpdateState(func(state *State) {
+		if try, ok := state.Tries[path]; ok {
+			try.AllowedHooks = ""
+		}
+	})
+}
+
+func hooksIn(path string) []string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	if !info.IsDir() {
+		if isExecutable(info) {
+			return []string{path}
+		}
+		fmt.Fprintf(os.Stderr, "Warning: hook %s is not executable, skipping\n", path)
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+
+	var hooks []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || !isExecutable(info) {
+			continue
+		}
+		hooks = append(hooks, filepath.Join(path, entry.Name()))
+	}
+	sort.Strings(hooks)
+
+	return hooks
+}
+
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// RunHooks runs the hooks for event on the try at path, stopping at the
+// first one that fails or exceeds hooks.timeout (in seconds). Hooks run
+// inside the try with TRY_EVENT, TRY_DIR and TRY_NAME set. Setting
+// TRY_SKIP_HOOKS disables them entirely.
+func RunHooks(event, path string, output io.Writer) error {
+	return RunHooksContext(context.Background(), event, path, output)
+}
+
+// RunHooksContext is RunHooks with the running hook killed when ctx is
+// cancelled
+func RunHooksContext(ctx context.Context, event, path string, output io.Writer) error {
+	if os.Getenv("TRY_SKIP_HOOKS") != "" {
+		return nil
+	}
+
+	hooks := FindHooks(event, path)
+	if !tryHooksAllowed(path) && len(hooksIn(filepath.Join(tryHooksDir(path), event))) > 0 {
+		fmt.Fprintf(output, "Skipping %s hooks of %s, review .try/hooks and run `try hooks allow %s` to enable them\n",
+			event, filepath.Base(path), filepath.Base(path))
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	timeout := defaultHookTimeout
+	if seconds := GetConfig().Int("hooks.timeout", 0); seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	env := append(os.Environ(),
+		"TRY_EVENT="+event,
+		"TRY_DIR="+path,
+		"TRY_NAME="+ExtractNameFromDirectory(filepath.Base(path)),
+	)
+
+	for _, hook := range hooks {
+		if err := runHook(ctx, hook, path, env, timeout, output); err != nil {
+			return &HookError{Event: event, Hook: hook, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func runHook(ctx context.Context, hook, dir string, env []string, timeout time.Duration, output io.Writer) error {
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(hookCtx, hook)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// runCreateHooks fires on-create for a freshly created try and removes the
+// try again if a hook aborts or ctx is cancelled
+func runCreateHooks(ctx context.Context, path string, output io.Writer) error {
+	if err := RunHooksContext(ctx, HookOnCreate, path, output); err != nil {
+		if isWorktree(path) {
+			removeWorktree(path)
+		}
+		os.RemoveAll(path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupHooks points the tries folder, config and state at temporary
+// directories and returns the global hooks directory
+func setupHooks(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in tests are shell scripts")
+	}
+
+	root := t.TempDir()
+	t.Setenv("TRY_PATH", filepath.Join(root, "tries"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("TRY_CONFIG", filepath.Join(root, "config", "try", "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	t.Setenv("TRY_SKIP_HOOKS", "")
+
+	return HooksDir()
+}
+
+func writeHook(t *testing.T, path, script string) {
+	t.Helper()
+
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateDirectoryRunsOnCreateHooks(t *testing.T) {
+	hooks := setupHooks(t)
+	log := filepath.Join(t.TempDir(), "log")
+
+	// A single executable and a directory of them both work
+	writeHook(t, filepath.Join(hooks, HookOnCreate), `echo "$TRY_EVENT $TRY_NAME $(pwd)" >> `+log)
+	writeHook(t, filepath.Join(hooks, HookOnDelete, "10-backup"), `echo "backup $TRY_DIR" >> `+log)
+
+	path, err := CreateDirectory("hooked")
+	if err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+
+	if err := DeleteDirectory(path); err != nil {
+		t.Fatalf("DeleteDirectory failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(log)
+	want := "on-create hooked " + path + "\nbackup " + path + "\n"
+	if string(content) != want {
+		t.Errorf("hook log = %q, want %q", content, want)
+	}
+}
+
+func TestFailingOnCreateHookRemovesTry(t *testing.T) {
+	hooks := setupHooks(t)
+	writeHook(t, filepath.Join(hooks, HookOnCreate), "exit 3")
+
+	_, err := CreateDirectory("vetoed")
+
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Event != HookOnCreate {
+		t.Fatalf("CreateDirectory error = %v, want an on-create HookError", err)
+	}
+
+	entries, _ := os.ReadDir(GetTryPath())
+	if len(entries) != 0 {
+		t.Errorf("try was not removed after the hook failed: %v", entries)
+	}
+}
+
+func TestFailingOnDeleteHookKeepsTry(t *testing.T) {
+	setupHooks(t)
+
+	path, err := CreateDirectory("keep")
+	if err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+
+	// Per-try hooks live inside the try itself
+	writeHook(t, filepath.Join(path, ".try", "hooks", HookOnDelete), "exit 1")
+	if err := AllowTryHooks(path); err != nil {
+		t.Fatalf("AllowTryHooks failed: %v", err)
+	}
+
+	if err := DeleteDirectory(path); err == nil {
+		t.Fatal("DeleteDirectory succeeded despite the failing hook")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("try was deleted: %v", err)
+	}
+
+	t.Setenv("TRY_SKIP_HOOKS", "1")
+	if err := DeleteDirectory(path); err != nil {
+		t.Errorf("DeleteDirectory with TRY_SKIP_HOOKS failed: %v", err)
+	}
+}
+
+func TestRunHooksTimeout(t *testing.T) {
+	hooks := setupHooks(t)
+	os.MkdirAll(filepath.Dir(ConfigPath()), 0755)
+	os.WriteFile(ConfigPath(), []byte("hooks.timeout: 1\n"), 0644)
+	writeHook(t, filepath.Join(hooks, HookOnEnter), "exec sleep 10")
+
+	err := RunHooks(HookOnEnter, t.TempDir(), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("RunHooks error = %v, want a timeout", err)
+	}
+}
+
+func TestCreateDirectoryContextCancel(t *testing.T) {
+	hooks := setupHooks(t)
+	writeHook(t, filepath.Join(hooks, HookOnCreate), "exec sleep 10")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := CreateDirectoryContext(ctx, "stopped")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CreateDirectoryContext error = %v, want the context's error", err)
+	}
+
+	entries, _ := os.ReadDir(GetTryPath())
+	if len(entries) != 0 {
+		t.Errorf("try was not removed after the hook was stopped: %v", entries)
+	}
+}
+
+func TestFindHooksOrder(t *testing.T) {
+	hooks := setupHooks(t)
+	try := t.TempDir()
+
+	writeHook(t, filepath.Join(hooks, HookOnEnter, "20-second"), "true")
+	writeHook(t, filepath.Join(hooks, HookOnEnter, "10-first"), "true")
+	os.WriteFile(filepath.Join(hooks, HookOnEnter, "README"), []byte("not a hook"), 0644)
+	writeHook(t, filepath.Join(try, ".try", "hooks", HookOnEnter), "true")
+	AllowTryHooks(try)
+
+	got := FindHooks(HookOnEnter, try)
+	want := []string{
+		filepath.Join(hooks, HookOnEnter, "10-first"),
+		filepath.Join(hooks, HookOnEnter, "20-second"),
+		filepath.Join(try, ".try", "hooks", HookOnEnter),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("FindHooks() = %q, want %q", got, want)
+	}
+}
+
+func TestTryHooksNeedAllowing(t *testing.T) {
+	setupHooks(t)
+	log := filepath.Join(t.TempDir(), "log")
+
+	// Hooks committed to a repository arrive with the clone
+	try := filepath.Join(GetTryPath(), "2025-08-30-cloned")
+	hook := filepath.Join(try, ".try", "hooks", HookOnEnter)
+	writeHook(t, hook, "echo ran >> "+log)
+
+	var output strings.Builder
+	if err := RunHooks(HookOnEnter, try, &output); err != nil {
+		t.Fatalf("RunHooks failed: %v", err)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Error("hook ran without being allowed")
+	}
+	if !strings.Contains(output.String(), "try hooks allow 2025-08-30-cloned") {
+		t.Errorf("output = %q, want a hint to allow the hooks", output.String())
+	}
+
+	if err := AllowTryHooks(try); err != nil {
+		t.Fatalf("AllowTryHooks failed: %v", err)
+	}
+	if got := FindHooks(HookOnEnter, try); len(got) != 1 || got[0] != hook {
+		t.Errorf("FindHooks() after allowing = %q, want %q", got, hook)
+	}
+
+	// A changed hook has to be allowed again
+	writeHook(t, hook, "echo changed >> "+log)
+	if got := FindHooks(HookOnEnter, try); len(got) != 0 {
+		t.Errorf("FindHooks() after a change = %q, want none", got)
+	}
+
+	AllowTryHooks(try)
+	if err := DenyTryHooks(try); err != nil {
+		t.Fatalf("DenyTryHooks failed: %v", err)
+	}
+	if got := FindHooks(HookOnEnter, try); len(got) != 0 {
+		t.Errorf("FindHooks() after denying = %q, want none", got)
+	}
+
+	if err := AllowTryHooks(t.TempDir()); err == nil {
+		t.Error("allowing a try without hooks succeeded, want error")
+	}
+}
+
+func TestCloneSkipsCommittedHooks(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	t.Setenv("TRY_SKIP_HOOKS", "")
+	log := filepath.Join(root, "log")
+
+	upstream := filepath.Join(root, "upstream")
+	writeHook(t, filepath.Join(upstream, ".try", "hooks", HookOnCreate), "echo pwned >> "+log)
+	gitCommand(t, upstream, "add", ".")
+	gitCommand(t, upstream, "commit", "--quiet", "-m", "hooks")
+
+	if _, err := CloneRepository(context.Background(), source, CloneOptions{}, io.Discard); err != nil {
+		t.Fatalf("CloneRepository failed: %v", err)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Error("on-create hook of the cloned repository ran")
+	}
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,11 +11,17 @@ import (
 	"time"
 )
 
+// GetTryPath returns the directory holding the tries: $TRY_PATH, the path
+// config key or ~/src/tries
 func GetTryPath() string {
 	if tryPath := os.Getenv("TRY_PATH"); tryPath != "" {
 		return tryPath
 	}
 	
+	if tryPath := GetConfig().String("path", ""); tryPath != "" {
+		return ExpandHome(tryPath)
+	}
+	
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return filepath.Join("/tmp", "tries")
@@ -32,29 +40,97 @@ func GenerateDatedName(name string) string {
 		name = "experiment"
 	}
 	
-	name = strings.ReplaceAll(name, " ", "-")
-	name = strings.ToLower(name)
+	name = normalizeName(name)
 	
 	date := time.Now().Format("2006-01-02")
 	baseName := fmt.Sprintf("%s-%s", date, name)
 	
-	tryPath := GetTryPath()
-	finalName := baseName
-	counter := 1
+	return uniqueName(GetTryPath(), baseName)
+}
+
+// normalizeName turns what the user typed into a directory name
+func normalizeName(name string) string {
+	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "-")
+	return strings.ToLower(name)
+}
+
+// datePrefix returns the YYYY-MM-DD- prefix of a try's name, or "" when
+// it has none
+func datePrefix(name string) string {
+	if len(name) < 11 || name[10] != '-' {
+		return ""
+	}
+	if _, err := time.Parse("2006-01-02", name[:10]); err != nil {
+		return ""
+	}
+	return name[:11]
+}
+
+// CreateOptions controls how CreateDirectoryWith populates a new try
+type CreateOptions struct {
+	Template string // Template to copy into the try
+	Lang     string // Language to scaffold a project for
+	Commit   bool   // Make an initial commit after scaffolding
+}
+
+// CreateDirectory creates an empty dated try and fires its on-create hooks
+func CreateDirectory(name string) (string, error) {
+	return CreateDirectoryWith(name, CreateOptions{}, HookOutput)
+}
+
+// CreateDirectoryContext is CreateDirectory with the on-create hooks
+// killed when ctx is cancelled, which removes the try again
+func CreateDirectoryContext(ctx context.Context, name string) (string, error) {
+	path, err := createDirectory(name)
+	if err != nil {
+		return "", err
+	}
+	
+	if err := runCreateHooks(ctx, path, HookOutput); err != nil {
+		return "", err
+	}
+	
+	return path, nil
+}
+
+// CreateDirectoryWith creates a dated try, applies the template and language
+// scaffold in opts and fires the on-create hooks once the try is complete.
+// A failing template or hook removes the try again; a failing scaffold only
+// prints a warning since the try is still usable without it.
+func CreateDirectoryWith(name string, opts CreateOptions, output io.Writer) (string, error) {
+	// Catch a misspelled language before there is a try to clean up
+	if opts.Lang != "" {
+		if _, err := ScaffoldLanguage(opts.Lang); err != nil {
+			return "", err
+		}
+	}
+	
+	path, err := createDirectory(name)
+	if err != nil {
+		return "", err
+	}
 	
-	for {
-		fullPath := filepath.Join(tryPath, finalName)
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-			break
+	if opts.Template != "" {
+		if err := ApplyTemplate(opts.Template, path, output); err != nil {
+			os.RemoveAll(path)
+			return "", err
 		}
-		finalName = fmt.Sprintf("%s-%d", baseName, counter)
-		counter++
 	}
 	
-	return finalName
+	if opts.Lang != "" {
+		if err := ScaffoldDirectory(path, opts.Lang, ScaffoldOptions{Commit: opts.Commit}, output); err != nil {
+			fmt.Fprintf(output, "Warning: failed to scaffold %s project: %v\n", opts.Lang, err)
+		}
+	}
+	
+	if err := runCreateHooks(context.Background(), path, output); err != nil {
+		return "", err
+	}
+	
+	return path, nil
 }
 
-func CreateDirectory(name string) (string, error) {
+func createDirectory(name string) (string, error) {
 	if err := EnsureTryDirectory(); err != nil {
 		return "", fmt.Errorf("failed to ensure try directory: %w", err)
 	}
@@ -69,6 +145,50 @@ func CreateDirectory(name string) (string, error) {
 	return fullPath, nil
 }
 
+// RenameTarget returns the directory name dir gets when renamed to name.
+// The try keeps its date prefix unless name brings its own, and a name
+// that is taken gets a -1, -2, ... suffix like a new try would.
+func RenameTarget(dir Directory, name string) (string, error) {
+	name = normalizeName(name)
+	if name == "" || strings.ContainsRune(name, filepath.Separator) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid name %q", name)
+	}
+	
+	if datePrefix(name) == "" {
+		name = datePrefix(dir.Name) + name
+	}
+	if name == dir.Name {
+		return "", fmt.Errorf("%s already has that name", dir.Name)
+	}
+	
+	return uniqueName(filepath.Dir(dir.Path), name), nil
+}
+
+// RenameDirectory renames a try and returns its new path. Worktrees are
+// moved with git so their repository keeps track of them, and tags and
+// other state follow the try to its new path.
+func RenameDirectory(dir Directory, name string) (string, error) {
+	newName, err := RenameTarget(dir, name)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(filepath.Dir(dir.Path), newName)
+	
+	// A promoted alias is just a link, whatever it points to stays put
+	if dir.IsAlias {
+		if err := os.Rename(dir.Path, dest); err != nil {
+			return "", fmt.Errorf("failed to rename %s: %w", dir.Name, err)
+		}
+	} else if err := moveTry(dir.Path, dest); err != nil {
+		return "", err
+	}
+	
+	if err := UpdateState(func(state *State) { state.Move(dir.Path, dest) }); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return dest, nil
+}
+
 func DeleteDirectory(path string) error {
 	tryPath := GetTryPath()
 	
@@ -76,6 +196,28 @@ func DeleteDirectory(path string) error {
 		return fmt.Errorf("can only delete directories within %s", tryPath)
 	}
 	
+	// Hooks get a chance to back things up, or to veto the deletion
+	if err := RunHooks(HookOnDelete, path, HookOutput); err != nil {
+		return err
+	}
+	
+	if err := removeTry(path); err != nil {
+		return err
+	}
+	
+	if err := UpdateState(func(state *State) { state.Forget(path) }); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return nil
+}
+
+// removeTry removes a try from disk
+func removeTry(path string) error {
+	// Promoted aliases only remove the link, never the promoted directory
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return os.Remove(path)
+	}
+	
 	// Check if this is a git worktree and remove it properly if so
 	if isWorktree(path) {
 		if err := removeWorktree(path); err != nil {
@@ -150,30 +292,3 @@ func removeWorktree(worktreePath string) error {
 	
 	return nil
 }
-
-func ExtractNameFromGitURL(url string) string {
-	url = strings.TrimSuffix(url, ".git")
-	
-	parts := strings.Split(url, "/")
-	if len(parts) > 0 {
-		name := parts[len(parts)-1]
-		
-		if strings.Contains(url, "://") {
-			if len(parts) > 1 {
-				name = parts[len(parts)-1]
-			}
-		} else if strings.HasPrefix(url, "git@") {
-			colonParts := strings.Split(url, ":")
-			if len(colonParts) > 1 {
-				pathParts := strings.Split(colonParts[1], "/")
-				if len(pathParts) > 0 {
-					name = pathParts[len(pathParts)-1]
-				}
-			}
-		}
-		
-		return name
-	}
-	
-	return "repo"
-}
\ No newline at end of file
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenameTarget(t *testing.T) {
+	tries := t.TempDir()
+	os.MkdirAll(filepath.Join(tries, "2025-08-30-taken"), 0755)
+	dir := Directory{Name: "2025-08-30-redis", Path: filepath.Join(tries, "2025-08-30-redis")}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"redis bench", "2025-08-30-redis-bench"},
+		{"2025-09-01-redis", "2025-09-01-redis"},
+		{"taken", "2025-08-30-taken-1"},
+		{"Cache", "2025-08-30-cache"},
+	}
+	for _, tt := range tests {
+		got, err := RenameTarget(dir, tt.name)
+		if err != nil {
+			t.Errorf("RenameTarget(%q) failed: %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("RenameTarget(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Tries without a date keep going without one
+	undated := Directory{Name: "scratch", Path: filepath.Join(tries, "scratch")}
+	if got, _ := RenameTarget(undated, "notes"); got != "notes" {
+		t.Errorf("RenameTarget(undated) = %q, want notes", got)
+	}
+
+	for _, name := range []string{"", "redis", "a/b", ".trash"} {
+		if _, err := RenameTarget(dir, name); err == nil {
+			t.Errorf("RenameTarget(%q) succeeded, want error", name)
+		}
+	}
+}
+
+func TestRenameWorktree(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	worktree := filepath.Join(tries, "2025-08-30-repo-worktree")
+	gitCommand(t, repo, "worktree", "add", "--quiet", "-b", "feature", worktree)
+	TagDirectories([]Directory{{Path: worktree}}, []string{"wip"})
+
+	renamed, err := RenameDirectory(Directory{Name: filepath.Base(worktree), Path: worktree, IsWorktree: true}, "feature")
+	if err != nil {
+		t.Fatalf("RenameDirectory failed: %v", err)
+	}
+	if want := filepath.Join(tries, "2025-08-30-feature"); renamed != want {
+		t.Errorf("renamed to %s, want %s", renamed, want)
+	}
+
+	list := gitCommand(t, repo, "worktree", "list", "--porcelain")
+	if !strings.Contains(list, "worktree "+renamed+"\n") {
+		t.Errorf("git worktree list = %q, want %s", list, renamed)
+	}
+	if status := gitCommand(t, renamed, "status", "--porcelain"); status != "" {
+		t.Errorf("renamed worktree is broken or dirty: %q", status)
+	}
+
+	state, _ := LoadState()
+	if tags := state.Tags(renamed); len(tags) != 1 || tags[0] != "wip" {
+		t.Errorf("tags after rename = %q, want [wip]", tags)
+	}
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mirror is a bare mirror of a remote repository kept in the local cache.
+// Clones reference the mirror so only new objects come over the network.
+type Mirror struct {
+	Path     string
+	URL      string
+	LastUsed time.Time
+	Size     int64
+}
+
+// lastUsedFile is touched inside a mirror every time a clone uses it
+const lastUsedFile = "try-last-used"
+
+// MirrorRoot returns the directory holding cached mirrors: the cache.path
+// config key, $XDG_CACHE_HOME/try/mirrors or ~/.cache/try/mirrors
+func MirrorRoot() string {
+	if path := GetConfig().String("cache.path", ""); path != "" {
+		return ExpandHome(path)
+	}
+
+	return filepath.Join(CacheDir(), "mirrors")
+}
+
+// CacheDir returns the directory for data try can recreate:
+// $XDG_CACHE_HOME/try, falling back to ~/.cache/try
+func CacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "try")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/tmp", "try")
+	}
+
+	return filepath.Join(home, ".cache", "try")
+}
+
+// MirrorPath returns where the mirror for a repository lives, e.g.
+// ~/.cache/try/mirrors/github.com/user/repo.git. Local repositories have no
+// host and are keyed by name plus a hash of their URL.
+func MirrorPath(u GitURL) string {
+	if u.Host == "" {
+		sum := sha1.Sum([]byte(u.URL))
+		return filepath.Join(MirrorRoot(), "local", fmt.Sprintf("%s-%x.git", u.Name, sum[:4]))
+	}
+	return filepath.Join(MirrorRoot(), u.Host, filepath.FromSlash(u.Owner), u.Name+".git")
+}
+
+// canMirror reports whether a clone source is worth caching. Plain local
+// paths are already on disk; file:// URLs are allowed so the cache can be
+// used with local bare repositories.
+func canMirror(u GitURL) bool {
+	return u.Host != "" || strings.HasPrefix(u.URL, "file://")
+}
+
+// EnsureMirror creates the mirror for a repository, or brings an existing
+// one up to date, and returns its path
+func EnsureMirror(ctx context.Context, u GitURL, output io.Writer) (string, error) {
+	path := MirrorPath(u)
+
+	if _, err := os.Stat(path); err == nil {
+		if err := updateMirror(ctx, path, output); err != nil {
+			return "", err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create mirror directory: %w", err)
+		}
+
+		cmd := exec.CommandContext(ctx, "git", "clone", "--mirror", "--quiet", u.URL, path)
+		cmd.Stdout = output
+		cmd.Stderr = output
+		if err := cmd.Run(); err != nil {
+			os.RemoveAll(path)
+			return "", fmt.Errorf("failed to create mirror: %w", err)
+		}
+	}
+
+	touchMirror(path)
+	return path, nil
+}
+
+// UpdateMirror fetches new objects into an existing mirror
+func UpdateMirror(m Mirror, output io.Writer) error {
+	return updateMirror(context.Background(), m.Path, output)
+}
+
+func updateMirror(ctx context.Context, path string, output io.Writer) error {
+	cmd := exec.CommandContext(ctx, "git", "--git-dir", path, "remote", "update", "--prune")
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update mirror %s: %w", path, err)
+	}
+	return nil
+}
+
+func touchMirror(path string) {
+	os.WriteFile(filepath.Join(path, lastUsedFile), []byte(time.Now().Format(time.RFC3339)), 0644)
+}
+
+// ListMirrors returns every cached mirror, most recently used first
+func ListMirrors() ([]Mirror, error) {
+	root := MirrorRoot()
+	var mirrors []Mirror
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() || !strings.HasSuffix(path, ".git") {
+			return nil
+		}
+
+		mirror := Mirror{Path: path, Size: DirectorySize(path)}
+		mirror.URL, _ = runGit(path, "--git-dir", path, "config", "--get", "remote.origin.url")
+
+		if info, err := os.Stat(filepath.Join(path, lastUsedFile)); err == nil {
+			mirror.LastUsed = info.ModTime()
+		} else if info, err := d.Info(); err == nil {
+			mirror.LastUsed = info.ModTime()
+		}
+
+		mirrors = append(mirrors, mirror)
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mirrors: %w", err)
+	}
+
+	sort.Slice(mirrors, func(i, j int) bool {
+		return mirrors[i].LastUsed.After(mirrors[j].LastUsed)
+	})
+
+	return mirrors, nil
+}
+
+// GCMirrors removes mirrors that have not been used by a clone for maxAge
+// and lets git compact the rest. It returns the removed mirrors.
+func GCMirrors(maxAge time.Duration, output io.Writer) ([]Mirror, error) {
+	mirrors, err := ListMirrors()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Mirror
+	for _, mirror := range mirrors {
+		if time.Since(mirror.LastUsed) > maxAge {
+			if err := os.RemoveAll(mirror.Path); err != nil {
+				return removed, fmt.Errorf("failed to remove mirror %s: %w", mirror.Path, err)
+			}
+			removed = append(removed, mirror)
+			continue
+		}
+
+		cmd := exec.Command("git", "--git-dir", mirror.Path, "gc", "--auto", "--quiet")
+		cmd.Stdout = output
+		cmd.Stderr = output
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(output, "Warning: git gc failed for %s: %v\n", mirror.Path, err)
+		}
+	}
+
+	return removed, nil
+}
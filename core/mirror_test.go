@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupTestEnvironment points the tries folder, config and cache at
+// temporary directories and returns a file:// URL of a fresh repository
+func setupTestEnvironment(t *testing.T) (string, string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	t.Setenv("TRY_PATH", filepath.Join(root, "tries"))
+	t.Setenv("TRY_CONFIG", filepath.Join(root, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+	t.Setenv("GIT_AUTHOR_NAME", "Try Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "try@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Try Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "try@example.com")
+
+	repo := filepath.Join(root, "upstream")
+	gitCommand(t, root, "init", "--quiet", repo)
+	os.WriteFile(filepath.Join(repo, "README"), []byte("hello\n"), 0644)
+	gitCommand(t, repo, "add", ".")
+	gitCommand(t, repo, "commit", "--quiet", "-m", "initial")
+
+	return root, "file://" + repo
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	output, err := runGit(dir, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestCloneRepositoryUsesMirror(t *testing.T) {
+	_, source := setupTestEnvironment(t)
+
+	path, err := CloneRepository(context.Background(), source, CloneOptions{UseCache: true}, io.Discard)
+	if err != nil {
+		t.Fatalf("CloneRepository failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "README")); err != nil {
+		t.Errorf("clone is missing README: %v", err)
+	}
+
+	gitURL, _ := ParseGitURL(source)
+	mirror := MirrorPath(gitURL)
+	if _, err := os.Stat(filepath.Join(mirror, "HEAD")); err != nil {
+		t.Fatalf("mirror was not created at %s: %v", mirror, err)
+	}
+
+	// Clones are dissociated by default so deleting the cache is safe
+	if _, err := os.Stat(filepath.Join(path, ".git", "objects", "info", "alternates")); err == nil {
+		t.Errorf("clone still borrows objects from the mirror")
+	}
+
+	mirrors, err := ListMirrors()
+	if err != nil {
+		t.Fatalf("ListMirrors failed: %v", err)
+	}
+	if len(mirrors) != 1 || mirrors[0].URL != source {
+		t.Fatalf("ListMirrors() = %+v, want one mirror of %s", mirrors, source)
+	}
+}
+
+func TestUpdateMirrorFetchesNewCommits(t *testing.T) {
+	_, source := setupTestEnvironment(t)
+	repo := strings.TrimPrefix(source, "file://")
+
+	gitURL, _ := ParseGitURL(source)
+	mirror, err := EnsureMirror(context.Background(), gitURL, io.Discard)
+	if err != nil {
+		t.Fatalf("EnsureMirror failed: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(repo, "README"), []byte("changed\n"), 0644)
+	gitCommand(t, repo, "commit", "--quiet", "-am", "second")
+	head := gitCommand(t, repo, "rev-parse", "HEAD")
+
+	if err := UpdateMirror(Mirror{Path: mirror}, io.Discard); err != nil {
+		t.Fatalf("UpdateMirror failed: %v", err)
+	}
+
+	if got := gitCommand(t, mirror, "--git-dir", mirror, "rev-parse", "HEAD"); got != head {
+		t.Errorf("mirror HEAD = %s, want %s", got, head)
+	}
+}
+
+func TestGCMirrorsRemovesUnusedMirrors(t *testing.T) {
+	_, source := setupTestEnvironment(t)
+
+	gitURL, _ := ParseGitURL(source)
+	mirror, err := EnsureMirror(context.Background(), gitURL, io.Discard)
+	if err != nil {
+		t.Fatalf("EnsureMirror failed: %v", err)
+	}
+
+	removed, err := GCMirrors(time.Hour, io.Discard)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("GCMirrors(1h) removed %v, %v; want nothing", removed, err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(mirror, lastUsedFile), old, old)
+
+	removed, err = GCMirrors(24*time.Hour, io.Discard)
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("GCMirrors(24h) removed %v, %v; want the mirror", removed, err)
+	}
+	if _, err := os.Stat(mirror); !os.IsNotExist(err) {
+		t.Errorf("mirror %s still exists", mirror)
+	}
+}
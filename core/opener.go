@@ -0,0 +1,303 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EditorOpener is the opener for $VISUAL or $EDITOR
+const EditorOpener = "editor"
+
+// Opener launches tries in an editor or IDE
+type Opener struct {
+	Name      string
+	Args      []string // Program and arguments; {path} stands for the try
+	Detach    bool     // GUI app, started in the background
+	Workspace bool     // Opens several tries as one .code-workspace
+}
+
+// builtinOpeners are launchers that don't need any configuration. All of
+// them start a GUI and return, so try doesn't wait for them.
+var builtinOpeners = []Opener{
+	{Name: "code", Args: []string{"code", "{path}"}, Detach: true, Workspace: true},
+	{Name: "codium", Args: []string{"codium", "{path}"}, Detach: true, Workspace: true},
+	{Name: "cursor", Args: []string{"cursor", "{path}"}, Detach: true, Workspace: true},
+	{Name: "zed", Args: []string{"zed", "{path}"}, Detach: true},
+	{Name: "subl", Args: []string{"subl", "{path}"}, Detach: true},
+	{Name: "idea", Args: []string{"idea", "{path}"}, Detach: true},
+	{Name: "goland", Args: []string{"goland", "{path}"}, Detach: true},
+	{Name: "pycharm", Args: []string{"pycharm", "{path}"}, Detach: true},
+	{Name: "webstorm", Args: []string{"webstorm", "{path}"}, Detach: true},
+	{Name: "clion", Args: []string{"clion", "{path}"}, Detach: true},
+	{Name: "rustrover", Args: []string{"rustrover", "{path}"}, Detach: true},
+}
+
+func findBuiltinOpener(name string) (Opener, bool) {
+	for _, opener := range builtinOpeners {
+		if opener.Name == name {
+			return opener, true
+		}
+	}
+	return Opener{}, false
+}
+
+// FindOpener returns the opener called name: an open.<name> config entry
+// such as "nvim {path}", a built-in launcher like code or idea, or "editor"
+// for $VISUAL/$EDITOR
+func FindOpener(name string) (Opener, error) {
+	if command, ok := GetConfig().Get("open." + name); ok && name != "default" {
+		args, err := splitShellWords(command)
+		if err != nil {
+			return Opener{}, fmt.Errorf("invalid open.%s: %w", name, err)
+		}
+		if len(args) == 0 {
+			return Opener{}, fmt.Errorf("open.%s has no command", name)
+		}
+
+		// Configured commands for a known GUI launcher behave like it
+		opener := Opener{Name: name, Args: args}
+		if builtin, ok := findBuiltinOpener(filepath.Base(args[0])); ok {
+			opener.Detach = builtin.Detach
+			opener.Workspace = builtin.Workspace
+		}
+		return opener, nil
+	}
+
+	if name == EditorOpener {
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		args, err := splitShellWords(editor)
+		if err != nil {
+			return Opener{}, fmt.Errorf("invalid editor command: %w", err)
+		}
+		if len(args) == 0 {
+			args = []string{"vi"}
+		}
+		return Opener{Name: name, Args: append(args, "{path}")}, nil
+	}
+
+	if opener, ok := findBuiltinOpener(name); ok {
+		return opener, nil
+	}
+	return Opener{}, fmt.Errorf("unknown opener %q (add open.%s: <command> {path} to the config)", name, name)
+}
+
+// DefaultOpener returns the opener named by the open.default config key,
+// falling back to $VISUAL/$EDITOR
+func DefaultOpener() (Opener, error) {
+	return FindOpener(GetConfig().String("open.default", EditorOpener))
+}
+
+// OpenerNames lists the openers that can be used right now: the editor,
+// configured openers and built-in launchers found on PATH
+func OpenerNames() []string {
+	names := []string{EditorOpener}
+
+	var configured []string
+	for key := range GetConfig().Section("open") {
+		if key != "default" && key != EditorOpener {
+			configured = append(configured, key)
+		}
+	}
+	sort.Strings(configured)
+	names = append(names, configured...)
+
+	for _, opener := range builtinOpeners {
+		if contains(names, opener.Name) {
+			continue
+		}
+		if _, err := exec.LookPath(opener.Args[0]); err == nil {
+			names = append(names, opener.Name)
+		}
+	}
+	return names
+}
+
+// Command builds the command opening paths. Arguments containing {path}
+// are repeated for every path, and paths are appended when no argument
+// mentions {path}. Several paths are combined into one workspace for
+// openers that support it.
+func (o Opener) Command(paths []string) (*exec.Cmd, error) {
+	dir := paths[0]
+	if len(paths) > 1 && o.Workspace {
+		workspace, err := WriteCodeWorkspace(paths)
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{workspace}
+	}
+
+	var args []string
+	substituted := false
+	for _, arg := range o.Args[1:] {
+		if !strings.Contains(arg, "{path}") {
+			args = append(args, arg)
+			continue
+		}
+		substituted = true
+		for _, path := range paths {
+			args = append(args, strings.ReplaceAll(arg, "{path}", path))
+		}
+	}
+	if !substituted {
+		args = append(args, paths...)
+	}
+
+	program, err := exec.LookPath(o.Args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s is not installed", o.Args[0])
+	}
+
+	cmd := exec.Command(program, args...)
+	cmd.Dir = dir
+	return cmd, nil
+}
+
+// Open opens paths with the opener. GUI apps are started in the background
+// and left running; anything else gets the terminal until it exits.
+func (o Opener) Open(paths []string) error {
+	cmd, err := o.Command(paths)
+	if err != nil {
+		return err
+	}
+
+	if o.Detach {
+		return StartDetached(cmd)
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", o.Name, err)
+	}
+	return nil
+}
+
+// StartDetached starts cmd without a terminal in a session of its own, so
+// it keeps running after try and the terminal exit
+func StartDetached(cmd *exec.Cmd) error {
+	cmd.Stdin = nil
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", filepath.Base(cmd.Path), err)
+	}
+	return cmd.Process.Release()
+}
+
+// codeWorkspace is the part of a .code-workspace file try writes
+type codeWorkspace struct {
+	Folders []codeWorkspaceFolder `json:"folders"`
+}
+
+type codeWorkspaceFolder struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// WriteCodeWorkspace writes a multi-root .code-workspace for paths into the
+// cache and returns its location. The same set of tries always maps to the
+// same file, so editors remember its window state. The file is named after
+// a hash of the paths, since joining the try names soon exceeds the file
+// name limit.
+func WriteCodeWorkspace(paths []string) (string, error) {
+	workspace := codeWorkspace{}
+	for _, path := range paths {
+		name := ExtractNameFromDirectory(filepath.Base(path))
+		workspace.Folders = append(workspace.Folders, codeWorkspaceFolder{Name: name, Path: path})
+	}
+
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	hash := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+
+	data, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to write workspace: %w", err)
+	}
+
+	dir := filepath.Join(CacheDir(), "workspaces")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to write workspace: %w", err)
+	}
+
+	file := filepath.Join(dir, hex.EncodeToString(hash[:6])+".code-workspace")
+	if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write workspace: %w", err)
+	}
+	return file, nil
+}
+
+// splitShellWords splits a command line into words the way a POSIX shell
+// would, honouring single and double quotes and backslash escapes, so
+// commands can name programs or pass arguments containing spaces
+func splitShellWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && strings.ContainsRune("\\\"$`", runes[i+1]) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash in %q", command)
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
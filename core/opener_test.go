@@ -0,0 +1,163 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakePrograms puts empty executables with the given names on PATH
+func fakePrograms(t *testing.T, names ...string) string {
+	t.Helper()
+
+	bin := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+	return bin
+}
+
+func TestFindOpener(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("open.vim: nvim -O {path}\nopen.work: code --new-window {path}\nopen.app: \"/opt/My App/bin/app\" --title 'a b' {path}\nopen.broken: nvim \"{path}\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "hx --vsplit")
+
+	tests := []struct {
+		name string
+		want Opener
+	}{
+		{"vim", Opener{Name: "vim", Args: []string{"nvim", "-O", "{path}"}}},
+		{"work", Opener{Name: "work", Args: []string{"code", "--new-window", "{path}"}, Detach: true, Workspace: true}},
+		{"app", Opener{Name: "app", Args: []string{"/opt/My App/bin/app", "--title", "a b", "{path}"}}},
+		{"editor", Opener{Name: "editor", Args: []string{"hx", "--vsplit", "{path}"}}},
+		{"idea", Opener{Name: "idea", Args: []string{"idea", "{path}"}, Detach: true}},
+	}
+
+	for _, tt := range tests {
+		got, err := FindOpener(tt.name)
+		if err != nil {
+			t.Errorf("FindOpener(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindOpener(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, name := range []string{"notepad", "broken"} {
+		if _, err := FindOpener(name); err == nil {
+			t.Errorf("FindOpener(%s) succeeded, want error", name)
+		}
+	}
+}
+
+func TestOpenerCommand(t *testing.T) {
+	bin := fakePrograms(t, "nvim", "code")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	paths := []string{"/tries/2025-08-30-redis", "/tries/2025-08-31-api"}
+
+	nvim := Opener{Name: "vim", Args: []string{"nvim", "-c", "cd {path}", "{path}"}}
+	cmd, err := nvim.Command(paths[:1])
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	want := []string{filepath.Join(bin, "nvim"), "-c", "cd /tries/2025-08-30-redis", "/tries/2025-08-30-redis"}
+	if !reflect.DeepEqual(cmd.Args, want) || cmd.Dir != paths[0] {
+		t.Errorf("Command = %q in %s, want %q in %s", cmd.Args, cmd.Dir, want, paths[0])
+	}
+
+	// Without {path}, paths are appended
+	cmd, _ = Opener{Args: []string{"nvim", "-O"}}.Command(paths)
+	if want := []string{filepath.Join(bin, "nvim"), "-O", paths[0], paths[1]}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("Command = %q, want %q", cmd.Args, want)
+	}
+
+	// VS Code gets one workspace holding every try
+	code, _ := FindOpener("code")
+	cmd, err = code.Command(paths)
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	workspace := cmd.Args[len(cmd.Args)-1]
+	if !strings.HasSuffix(workspace, ".code-workspace") {
+		t.Fatalf("code opened %s, want the workspace", workspace)
+	}
+
+	data, err := os.ReadFile(workspace)
+	if err != nil {
+		t.Fatalf("failed to read workspace: %v", err)
+	}
+	var got codeWorkspace
+	json.Unmarshal(data, &got)
+	wantFolders := []codeWorkspaceFolder{{"redis", paths[0]}, {"api", paths[1]}}
+	if !reflect.DeepEqual(got.Folders, wantFolders) {
+		t.Errorf("workspace folders = %+v, want %+v", got.Folders, wantFolders)
+	}
+
+	// The same tries in any order share a workspace, and many tries still
+	// give a short file name
+	if again, _ := WriteCodeWorkspace([]string{paths[1], paths[0]}); again != workspace {
+		t.Errorf("reordered tries got workspace %s, want %s", again, workspace)
+	}
+	var many []string
+	for i := 0; i < 50; i++ {
+		many = append(many, fmt.Sprintf("/tries/2025-08-30-a-rather-long-experiment-%d", i))
+	}
+	if file, err := WriteCodeWorkspace(many); err != nil || len(filepath.Base(file)) > 64 {
+		t.Errorf("WriteCodeWorkspace with 50 tries = %s, %v", file, err)
+	}
+
+	if _, err := (Opener{Args: []string{"zed", "{path}"}}).Command(paths[:1]); err == nil {
+		t.Error("Command succeeded although zed isn't installed")
+	}
+}
+
+func TestOpenerNames(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("open.default: vim\nopen.vim: nvim {path}\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+	fakePrograms(t, "code", "idea")
+
+	want := []string{"editor", "vim", "code", "idea"}
+	if got := OpenerNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OpenerNames() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"nvim -O {path}", []string{"nvim", "-O", "{path}"}},
+		{"  code\t--new-window  ", []string{"code", "--new-window"}},
+		{`"/Applications/My Editor/bin/edit" {path}`, []string{"/Applications/My Editor/bin/edit", "{path}"}},
+		{`nvim -c 'cd {path}' {path}`, []string{"nvim", "-c", "cd {path}", "{path}"}},
+		{`emacs --eval "(message \"hi\")"`, []string{"emacs", "--eval", `(message "hi")`}},
+		{`my\ editor ''`, []string{"my editor", ""}},
+		{`a'b'"c"`, []string{"abc"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := splitShellWords(tt.command)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitShellWords(%q) = %q, %v, want %q", tt.command, got, err, tt.want)
+		}
+	}
+
+	for _, command := range []string{`code "unterminated`, "vim 'x", `nvim \`} {
+		if _, err := splitShellWords(command); err == nil {
+			t.Errorf("splitShellWords(%q) succeeded, want error", command)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GitProgress is one progress update from `git clone --progress`
+type GitProgress struct {
+	Phase   string // e.g. "Receiving objects"; empty for plain messages
+	Percent int    // 0-100, or -1 when the line carries no percentage
+	Line    string // The raw line
+}
+
+var gitProgressPattern = regexp.MustCompile(`^(?:remote:\s*)?([A-Za-z][A-Za-z ]*):\s+(\d+)%`)
+
+// ParseGitProgress parses a single line of git progress output such as
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 1.00 MiB/s"
+func ParseGitProgress(line string) GitProgress {
+	progress := GitProgress{Percent: -1, Line: line}
+
+	if match := gitProgressPattern.FindStringSubmatch(line); match != nil {
+		progress.Phase = strings.TrimSpace(match[1])
+		progress.Percent, _ = strconv.Atoi(match[2])
+	}
+
+	return progress
+}
+
+type progressWriter struct {
+	buf    []byte
+	report func(GitProgress)
+}
+
+// NewProgressWriter returns a writer that parses git progress output and
+// calls report for every line. Git redraws progress lines with carriage
+// returns, so both \r and \n end a line.
+func NewProgressWriter(report func(GitProgress)) io.Writer {
+	return &progressWriter{report: report}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+
+		line := strings.TrimSpace(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+		if line != "" {
+			w.report(ParseGitProgress(line))
+		}
+	}
+
+	return len(p), nil
+}
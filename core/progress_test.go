@@ -0,0 +1,81 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitProgress(t *testing.T) {
+	tests := []struct {
+		line        string
+		wantPhase   string
+		wantPercent int
+	}{
+		{"Receiving objects:  45% (450/1000), 1.20 MiB | 1.00 MiB/s", "Receiving objects", 45},
+		{"Receiving objects: 100% (1000/1000), 2.40 MiB | 1.00 MiB/s, done.", "Receiving objects", 100},
+		{"Resolving deltas:   0% (0/300)", "Resolving deltas", 0},
+		{"remote: Counting objects:  12% (12/100)", "Counting objects", 12},
+		{"remote: Compressing objects: 100% (80/80), done.", "Compressing objects", 100},
+		{"Updating files:  99% (990/1000)", "Updating files", 99},
+		{"Cloning into '2025-08-30-repo'...", "", -1},
+		{"remote: Enumerating objects: 1000, done.", "", -1},
+		{"warning: redirecting to https://example.com/repo.git/", "", -1},
+		{"", "", -1},
+	}
+
+	for _, tt := range tests {
+		got := ParseGitProgress(tt.line)
+		if got.Phase != tt.wantPhase || got.Percent != tt.wantPercent || got.Line != tt.line {
+			t.Errorf("ParseGitProgress(%q) = %+v, want phase %q and %d%%", tt.line, got, tt.wantPhase, tt.wantPercent)
+		}
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	tests := []struct {
+		name         string
+		writes       []string
+		want         []string
+		wantPercents []int
+	}{
+		{
+			name:         "carriage returns",
+			writes:       []string{"Receiving objects:  10% (1/10)\rReceiving objects:  50% (5/10)\rReceiving objects: 100% (10/10), done.\n"},
+			want:         []string{"Receiving objects:  10% (1/10)", "Receiving objects:  50% (5/10)", "Receiving objects: 100% (10/10), done."},
+			wantPercents: []int{10, 50, 100},
+		},
+		{
+			name:         "split writes",
+			writes:       []string{"Cloning into 'repo'...\nRecei", "ving objects:  3", "0% (3/10)\r", "\r\nResolving deltas: 100% (2/2)"},
+			want:         []string{"Cloning into 'repo'...", "Receiving objects:  30% (3/10)"},
+			wantPercents: []int{-1, 30},
+		},
+		{
+			name:   "blank lines",
+			writes: []string{"\r\n  \n\r"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			var percents []int
+			w := NewProgressWriter(func(progress GitProgress) {
+				lines = append(lines, progress.Line)
+				percents = append(percents, progress.Percent)
+			})
+			for _, write := range tt.writes {
+				if n, err := w.Write([]byte(write)); n != len(write) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v", write, n, err)
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("reported lines %q, want %q", lines, tt.want)
+			}
+			if !reflect.DeepEqual(percents, tt.wantPercents) {
+				t.Errorf("reported percents %v, want %v", percents, tt.wantPercents)
+			}
+		})
+	}
+}
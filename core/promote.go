@@ -0,0 +1,146 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// PromoteOptions controls how a try is promoted out of the tries folder
+type PromoteOptions struct {
+	// Dest is the new location. When empty the try is moved next to the
+	// tries folder (e.g. ~/src/<name>). When it names an existing directory
+	// the try is moved inside it.
+	Dest string
+	// KeepDate keeps the YYYY-MM-DD- prefix in the new directory name
+	KeepDate bool
+	// GitInit runs git init when the try is not a repository yet
+	GitInit bool
+}
+
+// PromoteDirectory moves a try to a permanent location and leaves a
+// symlink behind so the old name keeps resolving to the new place
+func PromoteDirectory(dir Directory, opts PromoteOptions) (string, error) {
+	if dir.IsAlias {
+		return "", fmt.Errorf("%s has already been promoted to %s", dir.Name, dir.AliasTarget)
+	}
+
+	name := dir.Name
+	if !opts.KeepDate {
+		name = ExtractNameFromDirectory(name)
+	}
+
+	dest := ExpandHome(opts.Dest)
+	if dest == "" {
+		dest = filepath.Join(filepath.Dir(GetTryPath()), name)
+	} else if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, name)
+	}
+
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if _, err := os.Lstat(dest); err == nil {
+		return "", fmt.Errorf("%s already exists", dest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := moveTry(dir.Path, dest); err != nil {
+		return "", err
+	}
+
+	// Leave a tombstone so `try <name>` still finds the experiment
+	if err := os.Symlink(dest, dir.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to leave alias at %s: %v\n", dir.Path, err)
+	}
+
+	if opts.GitInit && !dir.IsGitRepo && !dir.IsWorktree {
+		if _, err := runGit(dest, "init", "--quiet"); err != nil {
+			return dest, fmt.Errorf("failed to initialize git repository: %w", err)
+		}
+	}
+
+	return dest, nil
+}
+
+// moveTry moves a directory while keeping git worktree links intact.
+// Worktrees are repaired after the move so the parent repository learns
+// about the new location, and so are the links of repositories with linked
+// worktrees so those worktrees can still find them.
+func moveTry(src, dst string) error {
+	commonDir := ""
+	if isWorktree(src) {
+		adminDir, err := worktreeAdminDir(src)
+		if err != nil {
+			return err
+		}
+		if commonDir, err = worktreeCommonDir(adminDir); err != nil {
+			return err
+		}
+	}
+
+	if err := moveDirectory(src, dst); err != nil {
+		return fmt.Errorf("failed to move directory: %w", err)
+	}
+
+	if commonDir != "" {
+		if _, err := runGit(commonDir, "--git-dir", commonDir, "worktree", "repair", dst); err != nil {
+			return fmt.Errorf("failed to update worktree location: %w", err)
+		}
+		return nil
+	}
+
+	if info, err := os.Stat(filepath.Join(dst, ".git", "worktrees")); err == nil && info.IsDir() {
+		if _, err := runGit(dst, "worktree", "repair"); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to repair worktree links: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// moveDirectory renames src to dst. Renaming can't cross filesystems, e.g.
+// from a separately mounted tries folder to ~/src, so then src is copied
+// and removed instead.
+func moveDirectory(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := copyTree(src, dst, nil); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	// A rename keeps the try's time, and so does this
+	if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
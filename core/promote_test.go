@@ -0,0 +1,98 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromoteDirectory(t *testing.T) {
+	root := t.TempDir()
+	tries := filepath.Join(root, "tries")
+	t.Setenv("TRY_PATH", tries)
+	t.Setenv("TRY_CONFIG", filepath.Join(root, "config"))
+
+	src := filepath.Join(tries, "2025-08-30-redis")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "notes.md"), []byte("notes\n"), 0644)
+	dir := Directory{Name: "2025-08-30-redis", Path: src}
+
+	dest, err := PromoteDirectory(dir, PromoteOptions{})
+	if err != nil {
+		t.Fatalf("PromoteDirectory failed: %v", err)
+	}
+	if want := filepath.Join(root, "redis"); dest != want {
+		t.Errorf("promoted to %s, want %s", dest, want)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "notes.md")); err != nil {
+		t.Errorf("notes.md was not moved: %v", err)
+	}
+	if link, err := os.Readlink(src); err != nil || link != dest {
+		t.Errorf("alias at %s points to %q (%v), want %s", src, link, err, dest)
+	}
+
+	alias := Directory{Name: dir.Name, Path: src, IsAlias: true, AliasTarget: dest}
+	if _, err := PromoteDirectory(alias, PromoteOptions{}); err == nil {
+		t.Error("promoting an alias succeeded, want error")
+	}
+
+	// An existing directory as destination gets the try moved inside it
+	projects := filepath.Join(root, "projects")
+	os.MkdirAll(projects, 0755)
+	kept := filepath.Join(tries, "2025-08-31-kept")
+	os.MkdirAll(kept, 0755)
+	dest, err = PromoteDirectory(Directory{Name: "2025-08-31-kept", Path: kept}, PromoteOptions{Dest: projects, KeepDate: true})
+	if err != nil {
+		t.Fatalf("PromoteDirectory with KeepDate failed: %v", err)
+	}
+	if want := filepath.Join(projects, "2025-08-31-kept"); dest != want {
+		t.Errorf("promoted to %s, want %s", dest, want)
+	}
+
+	os.WriteFile(filepath.Join(projects, "taken"), nil, 0644)
+	taken := filepath.Join(tries, "2025-09-01-taken")
+	os.MkdirAll(taken, 0755)
+	if _, err := PromoteDirectory(Directory{Name: "2025-09-01-taken", Path: taken}, PromoteOptions{Dest: projects}); err == nil {
+		t.Error("promoting onto an existing directory succeeded, want error")
+	}
+}
+
+func TestPromoteDirectoryGitInit(t *testing.T) {
+	root, _ := setupTestEnvironment(t)
+	tries := filepath.Join(root, "tries")
+
+	src := filepath.Join(tries, "2025-08-30-api")
+	os.MkdirAll(src, 0755)
+	dest, err := PromoteDirectory(Directory{Name: "2025-08-30-api", Path: src}, PromoteOptions{GitInit: true})
+	if err != nil {
+		t.Fatalf("PromoteDirectory failed: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, ".git")); err != nil || !info.IsDir() {
+		t.Errorf("no git repository at %s: %v", dest, err)
+	}
+}
+
+func TestPromoteWorktree(t *testing.T) {
+	root, source := setupTestEnvironment(t)
+	tries := filepath.Join(root, "tries")
+
+	repo := filepath.Join(tries, "2025-08-30-repo")
+	gitCommand(t, root, "clone", "--quiet", source, repo)
+	worktree := filepath.Join(tries, "2025-08-30-repo-feature")
+	gitCommand(t, repo, "worktree", "add", "--quiet", "-b", "feature", worktree)
+
+	dir := Directory{Name: "2025-08-30-repo-feature", Path: worktree, IsWorktree: true}
+	dest, err := PromoteDirectory(dir, PromoteOptions{Dest: filepath.Join(root, "feature")})
+	if err != nil {
+		t.Fatalf("PromoteDirectory failed: %v", err)
+	}
+
+	if status := gitCommand(t, dest, "status", "--porcelain"); status != "" {
+		t.Errorf("promoted worktree is broken or dirty: %q", status)
+	}
+	list := gitCommand(t, repo, "worktree", "list", "--porcelain")
+	if !strings.Contains(list, "worktree "+dest+"\n") {
+		t.Errorf("git worktree list = %q, want %s", list, dest)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build darwin
+
+package core
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflink makes dst a copy-on-write clone of src with clonefile(2), which
+// APFS supports. dst must not exist.
+func reflink(src, dst string, mode os.FileMode) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package core
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflink makes dst a copy-on-write clone of src with the FICLONE ioctl,
+// which btrfs, XFS and others support. dst must not exist.
+func reflink(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package core
+
+import (
+	"errors"
+	"os"
+)
+
+// reflink is unsupported here, files are always copied
+func reflink(src, dst string, mode os.FileMode) error {
+	return errors.ErrUnsupported
+}
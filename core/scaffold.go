@@ -0,0 +1,192 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scaffold describes how to bootstrap a project for one language
+type Scaffold struct {
+	Tool      string                           // Initializer that must be on PATH
+	Args      func(data TemplateData) []string // Arguments for the initializer
+	Gitignore []string                         // Lines the .gitignore must contain
+}
+
+// ScaffoldOptions controls optional scaffolding steps
+type ScaffoldOptions struct {
+	Commit bool // Make an initial commit once everything is in place
+}
+
+var scaffolds = map[string]Scaffold{
+	"go": {
+		Tool: "go",
+		Args: func(data TemplateData) []string {
+			return []string{"mod", "init", data.ModulePath}
+		},
+		Gitignore: []string{"/bin/", "*.test", "*.out", "coverage.*"},
+	},
+	"rust": {
+		Tool: "cargo",
+		Args: func(data TemplateData) []string {
+			return []string{"init", "--name", crateName(data.Name)}
+		},
+		Gitignore: []string{"/target/"},
+	},
+	"node": {
+		Tool: "npm",
+		Args: func(data TemplateData) []string {
+			return []string{"init", "-y"}
+		},
+		Gitignore: []string{"node_modules/", "dist/", ".env", "npm-debug.log*"},
+	},
+	"python": {
+		Tool: "uv",
+		Args: func(data TemplateData) []string {
+			return []string{"init", "--name", data.Name}
+		},
+		Gitignore: []string{".venv/", "__pycache__/", "*.pyc", "dist/", "*.egg-info/"},
+	},
+}
+
+var scaffoldAliases = map[string]string{
+	"golang":     "go",
+	"rs":         "rust",
+	"js":         "node",
+	"javascript": "node",
+	"ts":         "node",
+	"typescript": "node",
+	"py":         "python",
+}
+
+// External commands are reached through these so tests can stub them
+var (
+	lookPath   = exec.LookPath
+	runCommand = func(dir string, output io.Writer, name string, args ...string) error {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		cmd.Stdout = output
+		cmd.Stderr = output
+		return cmd.Run()
+	}
+)
+
+var invalidCrateChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// crateName turns a try name into a valid cargo package name
+func crateName(name string) string {
+	name = strings.Trim(invalidCrateChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "try-" + name
+	}
+	return name
+}
+
+// ScaffoldLanguages returns the supported --lang values
+func ScaffoldLanguages() []string {
+	var languages []string
+	for lang := range scaffolds {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ScaffoldLanguage returns the scaffold language called lang, with aliases
+// such as golang resolved, or an error listing the available languages
+func ScaffoldLanguage(lang string) (string, error) {
+	name := strings.ToLower(lang)
+	if alias, ok := scaffoldAliases[name]; ok {
+		name = alias
+	}
+	if _, ok := scaffolds[name]; !ok {
+		return "", fmt.Errorf("unknown language %q (available: %s)", lang, strings.Join(ScaffoldLanguages(), ", "))
+	}
+	return name, nil
+}
+
+// ScaffoldDirectory bootstraps a project in path: git init, the language's
+// initializer when its toolchain is on PATH, a .gitignore with sensible
+// entries and optionally an initial commit. Missing tools are reported to
+// output and skipped rather than treated as errors.
+func ScaffoldDirectory(path, lang string, opts ScaffoldOptions, output io.Writer) error {
+	lang, err := ScaffoldLanguage(lang)
+	if err != nil {
+		return err
+	}
+	scaffold := scaffolds[lang]
+
+	// Initialize git first so initializers that would create their own
+	// repository (cargo, uv) notice it and leave it alone
+	_, gitErr := lookPath("git")
+	hasGit := gitErr == nil
+	if hasGit {
+		if err := runCommand(path, output, "git", "init", "--quiet"); err != nil {
+			return fmt.Errorf("git init failed: %w", err)
+		}
+	} else {
+		fmt.Fprintf(output, "Warning: git not found on PATH, skipping git init\n")
+	}
+
+	if _, err := lookPath(scaffold.Tool); err == nil {
+		if err := runCommand(path, output, scaffold.Tool, scaffold.Args(NewTemplateData(path))...); err != nil {
+			return fmt.Errorf("%s failed: %w", scaffold.Tool, err)
+		}
+	} else {
+		fmt.Fprintf(output, "Warning: %s not found on PATH, skipping %s project setup\n", scaffold.Tool, lang)
+	}
+
+	if err := ensureGitignore(path, scaffold.Gitignore); err != nil {
+		return fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+
+	if opts.Commit && hasGit {
+		if err := runCommand(path, output, "git", "add", "--all"); err != nil {
+			return fmt.Errorf("git add failed: %w", err)
+		}
+		if err := runCommand(path, output, "git", "commit", "--quiet", "-m", "Initial commit"); err != nil {
+			return fmt.Errorf("git commit failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureGitignore appends the lines missing from path/.gitignore, keeping
+// whatever an initializer already wrote there
+func ensureGitignore(path string, lines []string) error {
+	gitignore := filepath.Join(path, ".gitignore")
+
+	existing, err := os.ReadFile(gitignore)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	present := map[string]bool{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, line := range lines {
+		if !present[line] {
+			missing = append(missing, line)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += strings.Join(missing, "\n") + "\n"
+
+	return os.WriteFile(gitignore, []byte(content), 0644)
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubCommands replaces external commands with a recorder. Only the tools
+// in available are considered to be on PATH.
+func stubCommands(t *testing.T, available ...string) *[]string {
+	t.Helper()
+
+	var calls []string
+	origLookPath, origRunCommand := lookPath, runCommand
+	t.Cleanup(func() {
+		lookPath, runCommand = origLookPath, origRunCommand
+	})
+
+	lookPath = func(name string) (string, error) {
+		for _, tool := range available {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", fmt.Errorf("%s not found", name)
+	}
+	runCommand = func(dir string, output io.Writer, name string, args ...string) error {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		return nil
+	}
+
+	return &calls
+}
+
+func TestScaffoldDirectory(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("author: Test\ntemplate.module-prefix: github.com/me\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+
+	tests := []struct {
+		lang      string
+		wantCalls []string
+		wantLine  string
+	}{
+		{"go", []string{"git init --quiet", "go mod init github.com/me/my-tool"}, "/bin/"},
+		{"golang", []string{"git init --quiet", "go mod init github.com/me/my-tool"}, "*.test"},
+		{"rust", []string{"git init --quiet", "cargo init --name my-tool"}, "/target/"},
+		{"node", []string{"git init --quiet", "npm init -y"}, "node_modules/"},
+		{"python", []string{"git init --quiet", "uv init --name my-tool"}, ".venv/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			calls := stubCommands(t, "git", "go", "cargo", "npm", "uv")
+			path := filepath.Join(t.TempDir(), "2025-08-30-my-tool")
+			os.Mkdir(path, 0755)
+
+			if err := ScaffoldDirectory(path, tt.lang, ScaffoldOptions{}, io.Discard); err != nil {
+				t.Fatalf("ScaffoldDirectory failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(*calls, tt.wantCalls) {
+				t.Errorf("commands = %q, want %q", *calls, tt.wantCalls)
+			}
+
+			gitignore, _ := os.ReadFile(filepath.Join(path, ".gitignore"))
+			if !strings.Contains(string(gitignore), tt.wantLine+"\n") {
+				t.Errorf(".gitignore = %q, want it to contain %q", gitignore, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestScaffoldDirectoryCommit(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "missing"))
+	calls := stubCommands(t, "git", "npm")
+	path := t.TempDir()
+
+	if err := ScaffoldDirectory(path, "js", ScaffoldOptions{Commit: true}, io.Discard); err != nil {
+		t.Fatalf("ScaffoldDirectory failed: %v", err)
+	}
+
+	want := []string{"git init --quiet", "npm init -y", "git add --all", "git commit --quiet -m Initial commit"}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("commands = %q, want %q", *calls, want)
+	}
+}
+
+func TestScaffoldDirectoryMissingToolchain(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "missing"))
+	calls := stubCommands(t)
+	path := t.TempDir()
+
+	var output strings.Builder
+	if err := ScaffoldDirectory(path, "rust", ScaffoldOptions{Commit: true}, &output); err != nil {
+		t.Fatalf("ScaffoldDirectory failed: %v", err)
+	}
+
+	if len(*calls) != 0 {
+		t.Errorf("commands = %q, want none", *calls)
+	}
+	if !strings.Contains(output.String(), "cargo not found") {
+		t.Errorf("output = %q, want a warning about cargo", output.String())
+	}
+	if _, err := os.Stat(filepath.Join(path, ".gitignore")); err != nil {
+		t.Errorf(".gitignore was not written: %v", err)
+	}
+}
+
+func TestScaffoldDirectoryUnknownLanguage(t *testing.T) {
+	stubCommands(t, "git")
+
+	if err := ScaffoldDirectory(t.TempDir(), "cobol", ScaffoldOptions{}, io.Discard); err == nil {
+		t.Error("ScaffoldDirectory(cobol) succeeded, want error")
+	}
+}
+
+func TestCreateDirectoryWithUnknownLanguage(t *testing.T) {
+	tries := filepath.Join(t.TempDir(), "tries")
+	t.Setenv("TRY_PATH", tries)
+	t.Setenv("TRY_SKIP_HOOKS", "1")
+
+	if _, err := CreateDirectoryWith("foo", CreateOptions{Lang: "cobol"}, io.Discard); err == nil {
+		t.Error("CreateDirectoryWith(cobol) succeeded, want error")
+	}
+	if entries, _ := os.ReadDir(tries); len(entries) > 0 {
+		t.Errorf("unknown language left a try behind: %v", entries)
+	}
+
+	if lang, err := ScaffoldLanguage("GoLang"); err != nil || lang != "go" {
+		t.Errorf("ScaffoldLanguage(GoLang) = %q, %v, want go", lang, err)
+	}
+}
+
+func TestEnsureGitignoreKeepsExistingEntries(t *testing.T) {
+	path := t.TempDir()
+	os.WriteFile(filepath.Join(path, ".gitignore"), []byte("/target\n.env"), 0644)
+
+	if err := ensureGitignore(path, []string{".env", "/target/"}); err != nil {
+		t.Fatalf("ensureGitignore failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(path, ".gitignore"))
+	if want := "/target\n.env\n/target/\n"; string(got) != want {
+		t.Errorf(".gitignore = %q, want %q", got, want)
+	}
+}
+
+func TestCrateName(t *testing.T) {
+	tests := map[string]string{
+		"my-tool":    "my-tool",
+		"My Tool.rs": "my-tool-rs",
+		"2fa":        "try-2fa",
+	}
+
+	for input, want := range tests {
+		if got := crateName(input); got != want {
+			t.Errorf("crateName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
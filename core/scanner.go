@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,11 +15,25 @@ type Directory struct {
 	CreatedTime  time.Time
 	ModifiedTime time.Time
 	AccessTime   time.Time
+	VisitedTime  time.Time // Last picked in the selector, zero if never
 	Score        float64
 	TextScore    float64
 	TimeScore    float64
 	IsGitRepo    bool
 	IsWorktree   bool
+	IsAlias      bool   // Symlink left behind by `try promote`
+	AliasTarget  string // Where the alias points to
+	Tags         []string
+	Size         int64  // Only measured for sorting by size or the size column
+	Branch       string // Checked out branch of repositories and worktrees
+}
+
+// TargetPath returns the directory to cd into, following promoted aliases
+func (d Directory) TargetPath() string {
+	if d.IsAlias && d.AliasTarget != "" {
+		return d.AliasTarget
+	}
+	return d.Path
 }
 
 func ScanDirectories() ([]Directory, error) {
@@ -33,25 +48,44 @@ func ScanDirectories() ([]Directory, error) {
 		return nil, err
 	}
 	
+	// Tags and visits are nice to have, a broken state file shouldn't hide the tries
+	state, _ := LoadState()
+	
 	var directories []Directory
 	
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		// Hidden directories hold trashed and archived tries, not tries
+		if strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 		
 		fullPath := filepath.Join(tryPath, entry.Name())
-		info, err := entry.Info()
-		if err != nil {
+		isAlias := entry.Type()&os.ModeSymlink != 0
+		if !entry.IsDir() && !isAlias {
+			continue
+		}
+		
+		// Aliases are followed so they show the promoted directory's details
+		info, err := os.Stat(fullPath)
+		if err != nil || !info.IsDir() {
 			continue
 		}
 		
 		dir := Directory{
 			Name:         entry.Name(),
 			Path:         fullPath,
-			CreatedTime:  info.ModTime(),
+			CreatedTime:  createdTime(entry.Name(), info.ModTime()),
 			ModifiedTime: info.ModTime(),
 			AccessTime:   info.ModTime(),
+			VisitedTime:  state.Visited(fullPath),
+			IsAlias:      isAlias,
+			Tags:         state.Tags(fullPath),
+		}
+		
+		if isAlias {
+			if target, err := filepath.EvalSymlinks(fullPath); err == nil {
+				dir.AliasTarget = target
+			}
 		}
 		
 		// Check if it's a git repository or worktree
@@ -65,6 +99,7 @@ func ScanDirectories() ([]Directory, error) {
 				dir.IsWorktree = true
 			}
 		}
+		dir.Branch = CurrentBranch(dir)
 		
 		directories = append(directories, dir)
 	}
@@ -72,6 +107,20 @@ func ScanDirectories() ([]Directory, error) {
 	return directories, nil
 }
 
+// createdTime is the date a try was created on, which its name starts
+// with. Directories without a date fall back to modTime.
+func createdTime(name string, modTime time.Time) time.Time {
+	prefix := datePrefix(name)
+	if prefix == "" {
+		return modTime
+	}
+	created, err := time.ParseInLocation("2006-01-02", prefix[:10], time.Local)
+	if err != nil {
+		return modTime
+	}
+	return created
+}
+
 func FilterAndScoreDirectories(directories []Directory, query string) []Directory {
 	scorer := NewScorer()
 	var scored []Directory
@@ -150,4 +199,79 @@ func GetRelativeAge(t time.Time) string {
 		return "1 year ago"
 	}
 	return fmt.Sprintf("%d years ago", years)
-}
\ No newline at end of file
+}
+
+// GetRelativeDate is GetRelativeAge for times known to the day only, like
+// the date a try was created on
+func GetRelativeDate(t time.Time) string {
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	if !t.Before(today) {
+		return "today"
+	}
+	if !t.Before(today.AddDate(0, 0, -1)) {
+		return "yesterday"
+	}
+	return GetRelativeAge(t)
+}
+
+// FindDirectory resolves a query to a single try. An exact match on the full
+// directory name or on the name without its date prefix wins; otherwise the
+// best scoring directory with a text match is returned.
+func FindDirectory(query string) (*Directory, error) {
+	directories, err := ScanDirectories()
+	if err != nil {
+		return nil, err
+	}
+	
+	queryLower := strings.ToLower(query)
+	for i, dir := range directories {
+		if strings.ToLower(dir.Name) == queryLower {
+			return &directories[i], nil
+		}
+	}
+	for i, dir := range directories {
+		if strings.ToLower(ExtractNameFromDirectory(dir.Name)) == queryLower {
+			return &directories[i], nil
+		}
+	}
+	
+	scored := FilterAndScoreDirectories(directories, query)
+	if len(scored) == 0 || query == "" {
+		return nil, fmt.Errorf("no try matches %q", query)
+	}
+	SortDirectoriesByScore(scored)
+	return &scored[0], nil
+}
+
+// DirectorySize returns the total size of the regular files below path
+func DirectorySize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// FormatSize renders a byte count in human readable form, e.g. "12.3 MB"
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreatedTime(t *testing.T) {
+	modTime := time.Date(2025, 9, 1, 15, 4, 5, 0, time.Local)
+
+	if got, want := createdTime("2025-08-30-redis", modTime), time.Date(2025, 8, 30, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("createdTime of a dated try = %v, want %v", got, want)
+	}
+	for _, name := range []string{"redis", "2025-02-30-typo", "2025-13-45-typo", "2025-08-30"} {
+		if got := createdTime(name, modTime); !got.Equal(modTime) {
+			t.Errorf("createdTime(%q) = %v, want the modification time", name, got)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Terminal multiplexers a try can be opened in
+const (
+	MultiplexerTmux   = "tmux"
+	MultiplexerZellij = "zellij"
+)
+
+// Multiplexers lists the supported terminal multiplexers
+var Multiplexers = []string{MultiplexerTmux, MultiplexerZellij}
+
+// SessionPane is one pane of a new session. An empty Command leaves a
+// plain shell.
+type SessionPane struct {
+	Command string
+}
+
+// SessionLayout describes how a new session is set up, read from config:
+//
+//	session.pane.1: nvim .
+//	session.pane.2:
+//	session.pane.3: go test ./...
+//	session.layout: main-vertical
+type SessionLayout struct {
+	Panes  []SessionPane
+	Layout string // tmux layout name, e.g. "tiled"
+}
+
+// DefaultMultiplexer returns the session.multiplexer config key, or the
+// multiplexer try is running in, falling back to tmux
+func DefaultMultiplexer() string {
+	if multiplexer := GetConfig().String("session.multiplexer", ""); multiplexer != "" {
+		return multiplexer
+	}
+	if os.Getenv("ZELLIJ") != "" {
+		return MultiplexerZellij
+	}
+	return MultiplexerTmux
+}
+
+// GetSessionLayout reads the layout for new sessions from config. Panes are
+// ordered by their number; without any there is a single shell.
+func GetSessionLayout() SessionLayout {
+	config := GetConfig()
+	layout := SessionLayout{Layout: config.String("session.layout", "")}
+
+	section := config.Section("session.pane")
+	numbers := make([]int, 0, len(section))
+	for key := range section {
+		if n, err := strconv.Atoi(key); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	for _, n := range numbers {
+		layout.Panes = append(layout.Panes, SessionPane{Command: section[strconv.Itoa(n)]})
+	}
+	if len(layout.Panes) == 0 {
+		layout.Panes = []SessionPane{{}}
+	}
+	return layout
+}
+
+// SessionName derives a session name from a try's directory: the name
+// without its date, reduced to letters, digits, - and _, which every
+// multiplexer accepts (tmux rejects . and :)
+func SessionName(path string) string {
+	name := sanitizeSessionName(ExtractNameFromDirectory(filepath.Base(path)))
+	if name == "" {
+		return "try"
+	}
+	return name
+}
+
+func sanitizeSessionName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			b.WriteRune(r)
+			dash = r == '-'
+		} else if !dash {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// OpenSession switches to the session for the try at path in the given
+// multiplexer, creating it with the configured layout unless it is already
+// running. Outside the multiplexer this attaches the terminal and returns
+// once the session is detached or ends.
+func OpenSession(multiplexer, path string) error {
+	if _, err := exec.LookPath(multiplexer); err != nil {
+		return fmt.Errorf("%s is not installed", multiplexer)
+	}
+
+	switch multiplexer {
+	case MultiplexerTmux:
+		return openTmuxSession(path, GetSessionLayout())
+	case MultiplexerZellij:
+		return openZellijSession(path, GetSessionLayout())
+	}
+	return fmt.Errorf("unsupported multiplexer %q (supported: %s)", multiplexer, strings.Join(Multiplexers, ", "))
+}
+
+func openTmuxSession(path string, layout SessionLayout) error {
+	name, exists := tmuxSessionName(path)
+	target := "=" + name
+
+	if !exists {
+		if err := createTmuxSession(name, path, layout); err != nil {
+			return err
+		}
+	}
+
+	if os.Getenv("TMUX") != "" {
+		if _, err := runMultiplexer("tmux", "switch-client", "-t", target); err != nil {
+			return fmt.Errorf("failed to switch to tmux session %s: %w", name, err)
+		}
+		return nil
+	}
+	return attachMultiplexer("", "tmux", "attach-session", "-t", target)
+}
+
+// tmuxSessionName picks the session for the try at path and reports whether
+// it is running. A session of the same name that belongs to another try,
+// e.g. an older one with the same name, makes the full directory name used
+// instead.
+func tmuxSessionName(path string) (string, bool) {
+	name := SessionName(path)
+	if !tmuxHasSession(name) {
+		return name, false
+	}
+
+	sessionPath, _ := runMultiplexer("tmux", "display-message", "-p", "-t", "="+name+":", "#{session_path}")
+	if sessionPath == path {
+		return name, true
+	}
+
+	full := sanitizeSessionName(filepath.Base(path))
+	return full, tmuxHasSession(full)
+}
+
+func tmuxHasSession(name string) bool {
+	_, err := runMultiplexer("tmux", "has-session", "-t", "="+name)
+	return err == nil
+}
+
+func createTmuxSession(name, path string, layout SessionLayout) error {
+	first, err := runMultiplexer("tmux", "new-session", "-d", "-s", name, "-c", path, "-P", "-F", "#{pane_id}")
+	if err != nil {
+		return fmt.Errorf("failed to create tmux session %s: %w", name, err)
+	}
+
+	// Pane ids stay valid whatever base-index and pane-base-index are set to
+	panes := []string{first}
+	for range layout.Panes[1:] {
+		pane, err := runMultiplexer("tmux", "split-window", "-t", first, "-c", path, "-P", "-F", "#{pane_id}")
+		if err != nil {
+			return fmt.Errorf("failed to split tmux session %s: %w", name, err)
+		}
+		panes = append(panes, pane)
+	}
+
+	if layout.Layout != "" {
+		if _, err := runMultiplexer("tmux", "select-layout", "-t", first, layout.Layout); err != nil {
+			return fmt.Errorf("failed to apply tmux layout %s: %w", layout.Layout, err)
+		}
+	}
+
+	// Commands are typed into the panes' shells, so a pane stays usable
+	// after its command exits
+	for i, pane := range layout.Panes {
+		if pane.Command == "" {
+			continue
+		}
+		if _, err := runMultiplexer("tmux", "send-keys", "-t", panes[i], pane.Command, "Enter"); err != nil {
+			return fmt.Errorf("failed to start %q in tmux: %w", pane.Command, err)
+		}
+	}
+
+	_, err = runMultiplexer("tmux", "select-pane", "-t", first)
+	return err
+}
+
+// openZellijSession opens a tab named after the try when running inside
+// zellij, and a session of that name otherwise
+func openZellijSession(path string, layout SessionLayout) error {
+	name := SessionName(path)
+	inside := os.Getenv("ZELLIJ") != ""
+
+	list := []string{"list-sessions", "--short", "--no-formatting"}
+	if inside {
+		list = []string{"action", "query-tab-names"}
+	}
+	output, _ := runMultiplexer("zellij", list...)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != name {
+			continue
+		}
+		if inside {
+			_, err := runMultiplexer("zellij", "action", "go-to-tab-name", name)
+			return err
+		}
+		return attachMultiplexer("", "zellij", "attach", name)
+	}
+
+	layoutFile, err := writeZellijLayout(path, layout)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(layoutFile)
+
+	if inside {
+		if _, err := runMultiplexer("zellij", "action", "new-tab", "--name", name, "--cwd", path, "--layout", layoutFile); err != nil {
+			return fmt.Errorf("failed to open zellij tab %s: %w", name, err)
+		}
+		return nil
+	}
+	return attachMultiplexer(path, "zellij", "--session", name, "--layout", layoutFile)
+}
+
+// writeZellijLayout turns layout into a KDL layout file with zellij's usual
+// tab and status bars around panes placed side by side
+func writeZellijLayout(path string, layout SessionLayout) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "layout {\n    cwd %s\n", strconv.Quote(path))
+	b.WriteString("    default_tab_template {\n")
+	b.WriteString("        pane size=1 borderless=true { plugin location=\"zellij:tab-bar\"; }\n")
+	b.WriteString("        children\n")
+	b.WriteString("        pane size=2 borderless=true { plugin location=\"zellij:status-bar\"; }\n")
+	b.WriteString("    }\n")
+	b.WriteString("    pane split_direction=\"vertical\" {\n")
+	for _, pane := range layout.Panes {
+		if pane.Command == "" {
+			b.WriteString("        pane\n")
+			continue
+		}
+		fmt.Fprintf(&b, "        pane command=\"sh\" { args \"-c\" %s; }\n", strconv.Quote(pane.Command))
+	}
+	b.WriteString("    }\n}\n")
+
+	file, err := os.CreateTemp("", "try-layout-*.kdl")
+	if err != nil {
+		return "", fmt.Errorf("failed to write zellij layout: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write zellij layout: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// runMultiplexer runs a multiplexer command and returns its trimmed output
+func runMultiplexer(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// attachMultiplexer hands the terminal to a multiplexer client
+func attachMultiplexer(dir, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to attach to %s: %w", name, err)
+	}
+	return nil
+}
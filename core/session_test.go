@@ -0,0 +1,132 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeTmux puts a tmux on PATH that logs its arguments and knows about the
+// given sessions, mapping each name to its start directory
+func fakeTmux(t *testing.T, sessions map[string]string) func() []string {
+	t.Helper()
+
+	bin := t.TempDir()
+	log := filepath.Join(bin, "tmux.log")
+
+	var known strings.Builder
+	for name, path := range sessions {
+		known.WriteString("        =" + name + ") echo '" + path + "'; exit 0 ;;\n")
+	}
+
+	script := `#!/bin/sh
+echo "$*" >> "` + log + `"
+case "$1" in
+has-session|display-message)
+    for target; do :; done
+    [ "$1" = display-message ] && target=${4%:}
+    case "$target" in
+` + known.String() + `    esac
+    [ "$1" = has-session ] && exit 1
+    ;;
+new-session|split-window)
+    echo "%$(wc -l < "` + log + `" | tr -d ' ')"
+    ;;
+esac
+exit 0
+`
+	if err := os.WriteFile(filepath.Join(bin, "tmux"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	return func() []string {
+		data, _ := os.ReadFile(log)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	tests := map[string]string{
+		"/tries/2025-08-30-redis":         "redis",
+		"/tries/2025-08-30-v1.2 test:foo": "v1-2-test-foo",
+		"/tries/2025-08-30-...":           "try",
+		"/tries/scratch":                  "scratch",
+	}
+
+	for path, want := range tests {
+		if got := SessionName(path); got != want {
+			t.Errorf("SessionName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestOpenTmuxSessionCreatesLayout(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(config, []byte("session.pane.1: nvim .\nsession.pane.2:\nsession.pane.10: go test ./...\nsession.layout: main-vertical\n"), 0644)
+	t.Setenv("TRY_CONFIG", config)
+	calls := fakeTmux(t, nil)
+
+	path := "/tries/2025-08-30-redis"
+	if err := OpenSession(MultiplexerTmux, path); err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+
+	want := []string{
+		"has-session -t =redis",
+		"new-session -d -s redis -c " + path + " -P -F #{pane_id}",
+		"split-window -t %2 -c " + path + " -P -F #{pane_id}",
+		"split-window -t %2 -c " + path + " -P -F #{pane_id}",
+		"select-layout -t %2 main-vertical",
+		"send-keys -t %2 nvim . Enter",
+		"send-keys -t %4 go test ./... Enter",
+		"select-pane -t %2",
+		"switch-client -t =redis",
+	}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tmux calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOpenTmuxSessionReusesSession(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "config"))
+	calls := fakeTmux(t, map[string]string{"redis": "/tries/2025-08-30-redis"})
+
+	if err := OpenSession(MultiplexerTmux, "/tries/2025-08-30-redis"); err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+
+	want := []string{
+		"has-session -t =redis",
+		"display-message -p -t =redis: #{session_path}",
+		"switch-client -t =redis",
+	}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tmux calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOpenTmuxSessionAvoidsOtherTry(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "config"))
+	calls := fakeTmux(t, map[string]string{"redis": "/tries/2025-01-10-redis"})
+
+	if err := OpenSession(MultiplexerTmux, "/tries/2025-08-30-redis"); err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+
+	got := calls()
+	if last := got[len(got)-1]; last != "switch-client -t =2025-08-30-redis" {
+		t.Errorf("switched with %q, want the session named after the full directory", last)
+	}
+}
+
+func TestOpenSessionMissingMultiplexer(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	if err := OpenSession(MultiplexerTmux, "/tries/2025-08-30-redis"); err == nil {
+		t.Error("OpenSession succeeded without tmux on PATH")
+	}
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortMode is an order the selector can list tries in
+type SortMode string
+
+const (
+	SortScore    SortMode = "score"    // Best match first, most recent first without a query
+	SortModified SortMode = "modified" // Most recently modified first
+	SortCreated  SortMode = "created"  // Most recently created first
+	SortVisited  SortMode = "visited"  // Most recently picked first
+	SortName     SortMode = "name"     // Alphabetically, ignoring the date
+	SortSize     SortMode = "size"     // Largest first
+	SortType     SortMode = "type"     // Repositories, worktrees, promoted, then the rest
+)
+
+// SortModes lists the sort modes in the order the selector cycles through
+var SortModes = []SortMode{SortScore, SortModified, SortCreated, SortVisited, SortName, SortSize, SortType}
+
+// GroupMode is how the selector splits tries into sections
+type GroupMode string
+
+const (
+	GroupNone GroupMode = ""
+	GroupAge  GroupMode = "age"  // Today, this week, this month and older
+	GroupTag  GroupMode = "tag"  // By first tag
+	GroupType GroupMode = "type" // Repositories, worktrees, promoted and the rest
+)
+
+// GroupModes lists the groupings in the order the selector cycles through
+var GroupModes = []GroupMode{GroupNone, GroupAge, GroupTag, GroupType}
+
+// ParseSortMode checks that name is a sort mode
+func ParseSortMode(name string) (SortMode, error) {
+	for _, mode := range SortModes {
+		if string(mode) == name {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort mode %q", name)
+}
+
+// ParseGroupMode checks that name is a grouping
+func ParseGroupMode(name string) (GroupMode, error) {
+	for _, mode := range GroupModes {
+		if string(mode) == name {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown grouping %q", name)
+}
+
+// SortDirectories orders directories by mode. Ties, and sorting by score
+// without a query, fall back to the most recently modified first.
+func SortDirectories(directories []Directory, mode SortMode, query string) {
+	if mode == SortScore {
+		if query == "" {
+			SortDirectoriesByTime(directories)
+		} else {
+			SortDirectoriesByScore(directories)
+		}
+		return
+	}
+
+	sort.SliceStable(directories, func(i, j int) bool {
+		a, b := directories[i], directories[j]
+		switch mode {
+		case SortCreated:
+			if !a.CreatedTime.Equal(b.CreatedTime) {
+				return a.CreatedTime.After(b.CreatedTime)
+			}
+		case SortVisited:
+			if !a.VisitedTime.Equal(b.VisitedTime) {
+				return a.VisitedTime.After(b.VisitedTime)
+			}
+		case SortName:
+			nameA := strings.ToLower(ExtractNameFromDirectory(a.Name))
+			nameB := strings.ToLower(ExtractNameFromDirectory(b.Name))
+			if nameA != nameB {
+				return nameA < nameB
+			}
+		case SortSize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case SortType:
+			if typeRank(a) != typeRank(b) {
+				return typeRank(a) < typeRank(b)
+			}
+		}
+		return a.ModifiedTime.After(b.ModifiedTime)
+	})
+}
+
+// typeRank orders the kinds of tries for sorting and grouping by type
+func typeRank(dir Directory) int {
+	switch {
+	case dir.IsAlias:
+		return 2
+	case dir.IsWorktree:
+		return 1
+	case dir.IsGitRepo:
+		return 0
+	}
+	return 3
+}
+
+var typeGroupTitles = []string{"Git repositories", "Worktrees", "Promoted", "Directories"}
+
+// DirectoryGroup is a titled section of tries
+type DirectoryGroup struct {
+	Title       string
+	Directories []Directory
+}
+
+// GroupDirectories splits sorted directories into sections, keeping their
+// order within each section. Grouping by age uses the time the directories
+// are sorted by, when they are sorted by time. GroupNone returns a single
+// untitled group.
+func GroupDirectories(directories []Directory, group GroupMode, mode SortMode) []DirectoryGroup {
+	if group == GroupNone {
+		return []DirectoryGroup{{Directories: directories}}
+	}
+
+	var titles []string
+	sections := map[string][]Directory{}
+	for _, dir := range directories {
+		title := groupTitle(dir, group, mode)
+		if _, ok := sections[title]; !ok {
+			titles = append(titles, title)
+		}
+		sections[title] = append(sections[title], dir)
+	}
+
+	// Sections come in a fixed order rather than that of their first try
+	order := map[string]int{}
+	switch group {
+	case GroupAge:
+		for i, title := range []string{"Today", "This week", "This month", "Older", "Never visited"} {
+			order[title] = i
+		}
+	case GroupType:
+		for i, title := range typeGroupTitles {
+			order[title] = i
+		}
+	case GroupTag:
+		sort.Strings(titles)
+		order["Untagged"] = 1
+	}
+	sort.SliceStable(titles, func(i, j int) bool {
+		return order[titles[i]] < order[titles[j]]
+	})
+
+	groups := make([]DirectoryGroup, len(titles))
+	for i, title := range titles {
+		groups[i] = DirectoryGroup{Title: title, Directories: sections[title]}
+	}
+	return groups
+}
+
+func groupTitle(dir Directory, group GroupMode, mode SortMode) string {
+	switch group {
+	case GroupTag:
+		if len(dir.Tags) == 0 {
+			return "Untagged"
+		}
+		return "#" + dir.Tags[0]
+	case GroupType:
+		return typeGroupTitles[typeRank(dir)]
+	}
+
+	t := dir.ModifiedTime
+	switch mode {
+	case SortCreated:
+		t = dir.CreatedTime
+	case SortVisited:
+		if dir.VisitedTime.IsZero() {
+			return "Never visited"
+		}
+		t = dir.VisitedTime
+	}
+	return ageTitle(t, time.Now())
+}
+
+// ageTitle puts t into a calendar bucket relative to now
+func ageTitle(t, now time.Time) string {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	switch {
+	case !t.Before(today):
+		return "Today"
+	case !t.Before(today.AddDate(0, 0, -6)):
+		return "This week"
+	case !t.Before(today.AddDate(0, -1, 0)):
+		return "This month"
+	}
+	return "Older"
+}
+
+// MeasureDirectories fills in the Size of every directory. Promoted aliases
+// are measured at the directory they point to.
+func MeasureDirectories(directories []Directory) {
+	for i := range directories {
+		directories[i].Size = DirectorySize(directories[i].TargetPath())
+	}
+}
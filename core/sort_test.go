@@ -0,0 +1,101 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func directoryNames(directories []Directory) []string {
+	names := make([]string, len(directories))
+	for i, dir := range directories {
+		names[i] = dir.Name
+	}
+	return names
+}
+
+func TestSortDirectories(t *testing.T) {
+	now := time.Now()
+	dirs := []Directory{
+		{Name: "2025-08-01-zebra", CreatedTime: now.AddDate(0, 0, -30), ModifiedTime: now, Size: 10},
+		{Name: "2025-08-20-apple", CreatedTime: now.AddDate(0, 0, -10), ModifiedTime: now.Add(-time.Hour), VisitedTime: now, IsWorktree: true},
+		{Name: "2025-08-25-mango", CreatedTime: now.AddDate(0, 0, -5), ModifiedTime: now.Add(-2 * time.Hour), Size: 30, IsGitRepo: true},
+	}
+
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortScore, []string{"2025-08-01-zebra", "2025-08-20-apple", "2025-08-25-mango"}},
+		{SortModified, []string{"2025-08-01-zebra", "2025-08-20-apple", "2025-08-25-mango"}},
+		{SortCreated, []string{"2025-08-25-mango", "2025-08-20-apple", "2025-08-01-zebra"}},
+		{SortVisited, []string{"2025-08-20-apple", "2025-08-01-zebra", "2025-08-25-mango"}},
+		{SortName, []string{"2025-08-20-apple", "2025-08-25-mango", "2025-08-01-zebra"}},
+		{SortSize, []string{"2025-08-25-mango", "2025-08-01-zebra", "2025-08-20-apple"}},
+		{SortType, []string{"2025-08-25-mango", "2025-08-20-apple", "2025-08-01-zebra"}},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]Directory(nil), dirs...)
+		SortDirectories(sorted, tt.mode, "")
+		if got := directoryNames(sorted); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SortDirectories(%s) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestGroupDirectories(t *testing.T) {
+	now := time.Now()
+	dirs := []Directory{
+		{Name: "a", ModifiedTime: now, Tags: []string{"wip"}},
+		{Name: "b", ModifiedTime: now.AddDate(0, -3, 0)},
+		{Name: "c", ModifiedTime: now.AddDate(0, 0, -2), Tags: []string{"db", "wip"}, IsGitRepo: true},
+	}
+
+	tests := []struct {
+		group GroupMode
+		mode  SortMode
+		want  []DirectoryGroup
+	}{
+		{GroupNone, SortScore, []DirectoryGroup{{Directories: dirs}}},
+		{GroupTag, SortScore, []DirectoryGroup{
+			{Title: "#db", Directories: dirs[2:3]},
+			{Title: "#wip", Directories: dirs[0:1]},
+			{Title: "Untagged", Directories: dirs[1:2]},
+		}},
+		{GroupType, SortScore, []DirectoryGroup{
+			{Title: "Git repositories", Directories: dirs[2:3]},
+			{Title: "Directories", Directories: []Directory{dirs[0], dirs[1]}},
+		}},
+		{GroupAge, SortVisited, []DirectoryGroup{
+			{Title: "Never visited", Directories: dirs},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := GroupDirectories(dirs, tt.group, tt.mode); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GroupDirectories(%q, %s) = %+v, want %+v", tt.group, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestAgeTitle(t *testing.T) {
+	now := time.Date(2025, 9, 10, 15, 0, 0, 0, time.Local)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2025, 9, 10, 0, 0, 0, 0, time.Local), "Today"},
+		{time.Date(2025, 9, 9, 23, 0, 0, 0, time.Local), "This week"},
+		{time.Date(2025, 9, 4, 0, 0, 0, 0, time.Local), "This week"},
+		{time.Date(2025, 9, 3, 23, 0, 0, 0, time.Local), "This month"},
+		{time.Date(2025, 8, 10, 0, 0, 0, 0, time.Local), "This month"},
+		{time.Date(2025, 8, 9, 0, 0, 0, 0, time.Local), "Older"},
+	}
+
+	for _, tt := range tests {
+		if got := ageTitle(tt.t, now); got != tt.want {
+			t.Errorf("ageTitle(%s) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is what try remembers about tries between runs, keyed by the path
+// of the try inside the tries directory. Unlike the config it is written by
+// try itself and lives in ~/.local/state/try/state.json.
+type State struct {
+	Tries map[string]*TryState `json:"tries,omitempty"`
+	Sort  string               `json:"sort,omitempty"`  // Last sort mode picked in the selector
+	Group string               `json:"group,omitempty"` // Last grouping picked in the selector
+}
+
+// TryState is what try remembers about one try
+type TryState struct {
+	Tags    []string  `json:"tags,omitempty"`
+	Visited time.Time `json:"visited,omitzero"` // Last picked in the selector
+
+	// Fingerprint of the .try/hooks allowed to run, see AllowTryHooks
+	AllowedHooks string `json:"allowed_hooks,omitempty"`
+}
+
+// stateMu serializes read-modify-write cycles within this process
+var stateMu sync.Mutex
+
+// StatePath returns the state file location: $XDG_STATE_HOME/try/state.json,
+// falling back to ~/.local/state/try/state.json
+func StatePath() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "try", "state.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/tmp", "try", "state.json")
+	}
+
+	return filepath.Join(home, ".local", "state", "try", "state.json")
+}
+
+// LoadState reads the state file. Like LoadConfig it always returns a
+// usable state, which is empty when the file is missing or unreadable.
+func LoadState() (*State, error) {
+	state := &State{Tries: map[string]*TryState{}}
+
+	data, err := os.ReadFile(StatePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return &State{Tries: map[string]*TryState{}}, fmt.Errorf("failed to parse %s: %w", StatePath(), err)
+	}
+	if state.Tries == nil {
+		state.Tries = map[string]*TryState{}
+	}
+	return state, nil
+}
+
+// Save writes the state file, replacing it atomically so a crash never
+// leaves half a file behind
+func (s *State) Save() error {
+	path := StatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	s.prune()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// UpdateState loads the state, lets update change it and saves it again
+func UpdateState(update func(*State)) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	update(state)
+	return state.Save()
+}
+
+// Try returns the state of the try at path, creating it if needed
+func (s *State) Try(path string) *TryState {
+	try, ok := s.Tries[path]
+	if !ok {
+		try = &TryState{}
+		s.Tries[path] = try
+	}
+	return try
+}
+
+// Tags returns the tags of the try at path
+func (s *State) Tags(path string) []string {
+	if try, ok := s.Tries[path]; ok {
+		return try.Tags
+	}
+	return nil
+}
+
+// Visited returns when the try at path was last picked, or the zero time
+func (s *State) Visited(path string) time.Time {
+	if try, ok := s.Tries[path]; ok {
+		return try.Visited
+	}
+	return time.Time{}
+}
+
+// Move carries the state of a try over to its new path
+func (s *State) Move(from, to string) {
+	if try, ok := s.Tries[from]; ok {
+		s.Tries[to] = try
+		delete(s.Tries, from)
+	}
+}
+
+// Forget drops everything remembered about the try at path
+func (s *State) Forget(path string) {
+	delete(s.Tries, path)
+}
+
+// prune drops tries there is nothing left to remember about
+func (s *State) prune() {
+	for path, try := range s.Tries {
+		if len(try.Tags) == 0 && try.Visited.IsZero() && try.AllowedHooks == "" {
+			delete(s.Tries, path)
+		}
+	}
+}
+
+// EditTags applies edits to tags and returns the sorted result. Each edit
+// is a tag to add, optionally written with a leading +, or a tag to remove
+// written with a leading -.
+func EditTags(tags []string, edits []string) []string {
+	set := map[string]bool{}
+	for _, tag := range tags {
+		set[tag] = true
+	}
+
+	for _, edit := range edits {
+		if tag, ok := strings.CutPrefix(edit, "-"); ok {
+			delete(set, tag)
+		} else if tag := strings.TrimPrefix(edit, "+"); tag != "" {
+			set[tag] = true
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for tag := range set {
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// TagDirectories applies tag edits, see EditTags, to every directory
+func TagDirectories(directories []Directory, edits []string) error {
+	return UpdateState(func(state *State) {
+		for _, dir := range directories {
+			try := state.Try(dir.Path)
+			try.Tags = EditTags(try.Tags, edits)
+		}
+	})
+}
+
+// RecordVisit remembers that the try at path was picked just now, for
+// sorting by last visit
+func RecordVisit(path string) error {
+	return UpdateState(func(state *State) {
+		state.Try(path).Visited = time.Now()
+	})
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEditTags(t *testing.T) {
+	tests := []struct {
+		tags  []string
+		edits []string
+		want  []string
+	}{
+		{nil, []string{"wip"}, []string{"wip"}},
+		{[]string{"wip"}, []string{"+redis", "wip"}, []string{"redis", "wip"}},
+		{[]string{"redis", "wip"}, []string{"-wip"}, []string{"redis"}},
+		{[]string{"wip"}, []string{"-wip", "-missing"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := EditTags(tt.tags, tt.edits); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EditTags(%q, %q) = %q, want %q", tt.tags, tt.edits, got, tt.want)
+		}
+	}
+}
+
+func TestStateTagsFollowTheTry(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	t.Setenv("TRY_PATH", filepath.Join(root, "tries"))
+	t.Setenv("TRY_SKIP_HOOKS", "1")
+
+	path := filepath.Join(root, "tries", "2025-08-30-redis")
+	os.MkdirAll(path, 0755)
+
+	if err := TagDirectories([]Directory{{Path: path}}, []string{"wip", "db"}); err != nil {
+		t.Fatalf("TagDirectories failed: %v", err)
+	}
+
+	dirs, err := ScanDirectories()
+	if err != nil || len(dirs) != 1 {
+		t.Fatalf("ScanDirectories() = %v, %v", dirs, err)
+	}
+	if want := []string{"db", "wip"}; !reflect.DeepEqual(dirs[0].Tags, want) {
+		t.Errorf("tags = %q, want %q", dirs[0].Tags, want)
+	}
+
+	archived, err := ArchiveDirectory(path)
+	if err != nil {
+		t.Fatalf("ArchiveDirectory failed: %v", err)
+	}
+	state, _ := LoadState()
+	if state.Tags(path) != nil || len(state.Tags(archived)) != 2 {
+		t.Errorf("tags did not move with the try: %+v", state.Tries)
+	}
+
+	if err := DeleteDirectory(archived); err != nil {
+		t.Fatalf("DeleteDirectory failed: %v", err)
+	}
+	state, _ = LoadState()
+	if len(state.Tries) != 0 {
+		t.Errorf("state after delete = %+v, want empty", state.Tries)
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+)
+
+// SubshellEnv is set to the try's path inside shells started by try, so
+// prompts and scripts can tell they are in one
+const SubshellEnv = "TRY_SUBSHELL"
+
+// subshellPrompt prefixes the prompt of subshells started by try
+const subshellPrompt = "(try) "
+
+// StartSubshell runs the user's shell inside path and waits for it to exit.
+// The shell gets TRY_SUBSHELL and, for bash, zsh and fish, a prompt prefix
+// that shows it isn't the shell the user started in.
+func StartSubshell(path string) error {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	setup, err := os.MkdirTemp("", "try-subshell-")
+	if err != nil {
+		return fmt.Errorf("failed to prepare subshell: %w", err)
+	}
+	defer os.RemoveAll(setup)
+
+	args, env, err := subshellPromptSetup(filepath.Base(shell), setup)
+	if err != nil {
+		return fmt.Errorf("failed to prepare subshell: %w", err)
+	}
+
+	cmd := exec.Command(shell, args...)
+	cmd.Dir = path
+	cmd.Env = append(append(os.Environ(), SubshellEnv+"="+path), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Ctrl+C belongs to the subshell while it runs
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", shell, err)
+	}
+	// The exit status is whatever the user's last command returned
+	cmd.Wait()
+	return nil
+}
+
+// subshellPromptSetup returns the arguments and environment that make a
+// shell load the user's usual startup files and then prefix the prompt.
+// Files it needs are written to dir.
+func subshellPromptSetup(shell, dir string) ([]string, []string, error) {
+	switch shell {
+	case "bash":
+		rcfile := filepath.Join(dir, "bashrc")
+		script := `[ -f ~/.bashrc ] && . ~/.bashrc
+PS1="` + subshellPrompt + `$PS1"
+`
+		return []string{"--rcfile", rcfile}, nil, os.WriteFile(rcfile, []byte(script), 0600)
+
+	case "zsh":
+		// zsh reads its startup files from ZDOTDIR, so point it at files that
+		// source the user's originals. .zshenv keeps ZDOTDIR pointing at us
+		// until .zshrc has been read, then .zshrc restores the user's.
+		original := os.Getenv("ZDOTDIR")
+		if original == "" {
+			original = os.Getenv("HOME")
+		}
+		zshenv := `ZDOTDIR=` + shellQuote(original) + `
+[ -f "$ZDOTDIR/.zshenv" ] && . "$ZDOTDIR/.zshenv"
+__try_zdotdir=$ZDOTDIR
+ZDOTDIR=` + shellQuote(dir) + `
+`
+		zshrc := `ZDOTDIR=$__try_zdotdir
+unset __try_zdotdir
+[ -f "$ZDOTDIR/.zshrc" ] && . "$ZDOTDIR/.zshrc"
+PROMPT="` + subshellPrompt + `$PROMPT"
+`
+		if err := os.WriteFile(filepath.Join(dir, ".zshenv"), []byte(zshenv), 0600); err != nil {
+			return nil, nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".zshrc"), []byte(zshrc), 0600); err != nil {
+			return nil, nil, err
+		}
+		return nil, []string{"ZDOTDIR=" + dir}, nil
+
+	case "fish":
+		init := `functions -c fish_prompt __try_fish_prompt; function fish_prompt; echo -n '` + subshellPrompt + `'; __try_fish_prompt; end`
+		return []string{"--init-command", init}, nil, nil
+	}
+
+	return nil, nil, nil
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateMetaDir holds template metadata, such as the post-create hook,
+// and is never copied into new tries
+const templateMetaDir = ".try-template"
+
+// Template is a directory whose contents seed a new try
+type Template struct {
+	Name    string
+	Path    string
+	HasHook bool
+}
+
+// TemplateData is available to templates in file names and contents, e.g.
+// {{.Name}} or {{.ModulePath}}
+type TemplateData struct {
+	Name       string // Name without the date prefix, e.g. "foo"
+	DirName    string // Full directory name, e.g. "2025-08-30-foo"
+	Path       string // Absolute path of the new try
+	Date       string // Creation date, YYYY-MM-DD
+	Author     string // The author config key or git user.name
+	ModulePath string // template.module-prefix config key joined with Name
+}
+
+var templateFuncs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.ReplaceAll,
+}
+
+// TemplatesDir returns the directory holding templates,
+// ~/.config/try/templates by default
+func TemplatesDir() string {
+	return filepath.Join(ConfigDir(), "templates")
+}
+
+// ListTemplates returns the available templates sorted by name
+func ListTemplates() ([]Template, error) {
+	entries, err := os.ReadDir(TemplatesDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	var templates []Template
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(TemplatesDir(), entry.Name())
+		_, err := os.Stat(filepath.Join(path, templateMetaDir, "post-create"))
+		templates = append(templates, Template{
+			Name:    entry.Name(),
+			Path:    path,
+			HasHook: err == nil,
+		})
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// NewTemplateData returns the template variables for a try at path
+func NewTemplateData(path string) TemplateData {
+	dirName := filepath.Base(path)
+	name := ExtractNameFromDirectory(dirName)
+	config := GetConfig()
+
+	author := config.String("author", "")
+	if author == "" {
+		author, _ = runGit(path, "config", "--get", "user.name")
+	}
+
+	modulePath := name
+	if prefix := strings.TrimSuffix(config.String("template.module-prefix", ""), "/"); prefix != "" {
+		modulePath = prefix + "/" + name
+	}
+
+	return TemplateData{
+		Name:       name,
+		DirName:    dirName,
+		Path:       path,
+		Date:       time.Now().Format("2006-01-02"),
+		Author:     author,
+		ModulePath: modulePath,
+	}
+}
+
+// CreateDirectoryFromTemplate creates a dated try and populates it from a
+// template. The try is removed again if the template can't be applied.
+func CreateDirectoryFromTemplate(name, templateName string, output io.Writer) (string, error) {
+	return CreateDirectoryWith(name, CreateOptions{Template: templateName}, output)
+}
+
+// ApplyTemplate copies a template into dest, expanding text/template
+// expressions in file names and in the contents of .tmpl files, which lose
+// the suffix. Other files are copied as is, since {{ is common in workflow
+// files and charts. The template's .try-template/post-create script runs
+// last inside dest.
+func ApplyTemplate(templateName, dest string, output io.Writer) error {
+	source := filepath.Join(TemplatesDir(), templateName)
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return fmt.Errorf("template %q not found in %s", templateName, TemplatesDir())
+	}
+
+	data := NewTemplateData(dest)
+
+	err := filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if rel == templateMetaDir {
+			return filepath.SkipDir
+		}
+
+		rel, err = renderTemplate(rel, rel, data)
+		if err != nil {
+			return err
+		}
+		target, isTemplate := strings.CutSuffix(filepath.Join(dest, rel), ".tmpl")
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if isTemplate {
+			rendered, err := renderTemplate(rel, string(content), data)
+			if err != nil {
+				return err
+			}
+			content = []byte(rendered)
+		}
+
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply template %s: %w", templateName, err)
+	}
+
+	hook := filepath.Join(source, templateMetaDir, "post-create")
+	if _, err := os.Stat(hook); err == nil {
+		cmd := exec.Command(hook)
+		cmd.Dir = dest
+		cmd.Stdout = output
+		cmd.Stderr = output
+		cmd.Env = append(os.Environ(),
+			"TRY_TEMPLATE="+templateName,
+			"TRY_DIR="+dest,
+			"TRY_NAME="+data.Name,
+		)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("post-create hook of template %s failed: %w", templateName, err)
+		}
+	}
+
+	return nil
+}
+
+func renderTemplate(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTemplateFile creates a file below the templates directory
+func writeTemplateFile(t *testing.T, rel, content string, perm os.FileMode) {
+	t.Helper()
+
+	path := filepath.Join(TemplatesDir(), rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListTemplates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if templates, err := ListTemplates(); err != nil || templates != nil {
+		t.Errorf("ListTemplates() without a templates directory = %v, %v, want none", templates, err)
+	}
+
+	writeTemplateFile(t, "web/index.html", "", 0644)
+	writeTemplateFile(t, "go-cli/.try-template/post-create", "#!/bin/sh\n", 0755)
+	writeTemplateFile(t, ".hidden/file", "", 0644)
+	writeTemplateFile(t, "README", "", 0644)
+
+	templates, err := ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates failed: %v", err)
+	}
+	want := []Template{
+		{Name: "go-cli", Path: filepath.Join(TemplatesDir(), "go-cli"), HasHook: true},
+		{Name: "web", Path: filepath.Join(TemplatesDir(), "web")},
+	}
+	if !reflect.DeepEqual(templates, want) {
+		t.Errorf("ListTemplates() = %+v, want %+v", templates, want)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{Name: "My Tool", DirName: "2025-08-30-my-tool", ModulePath: "github.com/me/my-tool"}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"module {{.ModulePath}}", "module github.com/me/my-tool"},
+		{"{{replace (lower .Name) \" \" \"-\"}}", "my-tool"},
+		{"{{upper .DirName}}", "2025-08-30-MY-TOOL"},
+		{"no expressions", "no expressions"},
+	}
+	for _, tt := range tests {
+		got, err := renderTemplate("test", tt.text, data)
+		if err != nil || got != tt.want {
+			t.Errorf("renderTemplate(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+		}
+	}
+
+	for _, text := range []string{"{{.Missing}}", "{{.Name", "{{ secrets.TOKEN }}"} {
+		if _, err := renderTemplate("test", text, data); err == nil {
+			t.Errorf("renderTemplate(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("TRY_CONFIG", filepath.Join(root, "config", "try", "config"))
+	os.MkdirAll(filepath.Join(root, "config", "try"), 0755)
+	os.WriteFile(filepath.Join(root, "config", "try", "config"), []byte("author: Ada\ntemplate.module-prefix: github.com/ada/\n"), 0644)
+
+	workflow := "steps:\n  - run: echo ${{ secrets.TOKEN }}\n"
+	writeTemplateFile(t, "go-cli/go.mod.tmpl", "module {{.ModulePath}}\n// by {{.Author}}\n", 0644)
+	writeTemplateFile(t, "go-cli/.github/workflows/ci.yml", workflow, 0644)
+	writeTemplateFile(t, "go-cli/cmd/{{.Name}}/main.go", "package main\n", 0644)
+	writeTemplateFile(t, "go-cli/logo.png", "\x89PNG\x00{{", 0644)
+	writeTemplateFile(t, "go-cli/run.sh", "#!/bin/sh\n", 0755)
+	writeTemplateFile(t, "go-cli/.try-template/post-create", "#!/bin/sh\necho \"$TRY_TEMPLATE $TRY_NAME\" > created\n", 0755)
+
+	dest := filepath.Join(root, "tries", "2025-08-30-tool")
+	os.MkdirAll(dest, 0755)
+	if err := ApplyTemplate("go-cli", dest, io.Discard); err != nil {
+		t.Fatalf("ApplyTemplate failed: %v", err)
+	}
+
+	files := map[string]string{
+		"go.mod":                   "module github.com/ada/tool\n// by Ada\n",
+		".github/workflows/ci.yml": workflow,
+		"cmd/tool/main.go":         "package main\n",
+		"logo.png":                 "\x89PNG\x00{{",
+		"created":                  "go-cli tool\n",
+	}
+	for rel, want := range files {
+		if got, err := os.ReadFile(filepath.Join(dest, rel)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", rel, got, err, want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dest, "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh lost its permissions: %v, %v", info, err)
+	}
+	for _, rel := range []string{"go.mod.tmpl", ".try-template"} {
+		if _, err := os.Stat(filepath.Join(dest, rel)); !os.IsNotExist(err) {
+			t.Errorf("%s was copied into the try", rel)
+		}
+	}
+
+	if err := ApplyTemplate("missing", dest, io.Discard); err == nil {
+		t.Error("applying a missing template succeeded, want error")
+	}
+}
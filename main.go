@@ -3,17 +3,18 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/zengjie/try/cmd"
-	"github.com/zengjie/try/shell"
+	"github.com/zengjie/try/core"
 	"github.com/zengjie/try/ui"
 )
 
 func main() {
+	cmd.CheckWrapper(os.Stderr)
+
 	if len(os.Args) < 2 {
-		if err := cmd.RunInteractiveSelector(""); err != nil {
+		if err := cmd.RunInteractiveSelector("", cmd.SelectorOptions{}); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -27,29 +28,18 @@ func main() {
 		showHelp()
 		return
 	case "init":
-		var tryPath string
-		if len(os.Args) > 2 {
-			tryPath = os.Args[2]
-		} else {
-			home, _ := os.UserHomeDir()
-			tryPath = filepath.Join(home, "src", "tries")
-		}
-		
-		shellName := os.Getenv("SHELL")
-		if shellName == "" {
-			shellName = "bash"
+		if err := cmd.RunInit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		shellName = filepath.Base(shellName)
-		
-		script := shell.GenerateShellScript(shellName, tryPath)
-		fmt.Print(script)
 
 	case "new":
-		name := ""
-		if len(os.Args) > 2 {
-			name = strings.Join(os.Args[2:], " ")
+		name, opts, err := cmd.ParseNewArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		if err := cmd.CreateNewDirectory(name); err != nil {
+		if err := cmd.CreateNewDirectory(name, opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -65,11 +55,12 @@ func main() {
 		}
 
 	case "clone":
-		if len(os.Args) < 3 {
-			fmt.Fprintf(os.Stderr, "Error: git URL required\n")
+		source, opts, err := core.ParseCloneArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		if err := cmd.CloneRepository(os.Args[2]); err != nil {
+		if err := cmd.CloneRepository(source, opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -88,16 +79,125 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "detach-worktree":
+		if len(os.Args) < 3 {
+			fmt.Fprintf(os.Stderr, "Error: worktree name required\n")
+			os.Exit(1)
+		}
+		if err := cmd.DetachWorktree(strings.Join(os.Args[2:], " ")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "completion":
+		if err := cmd.RunCompletion(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "__complete":
+		cmd.RunComplete(os.Args[2:])
+
+	case "templates":
+		if err := cmd.ListTemplates(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "cache":
+		if err := cmd.RunCache(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "hooks":
+		if err := cmd.RunHooks(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "open":
+		queries, opts, err := cmd.ParseOpenArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.OpenDirectories(queries, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "exec":
+		code, err := cmd.RunExec(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(code)
+
+	case "fork":
+		query, opts, err := cmd.ParseForkArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.ForkDirectory(query, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "rename":
+		if len(os.Args) != 4 {
+			fmt.Fprintf(os.Stderr, "Error: usage: try rename <name> <new-name>\n")
+			os.Exit(1)
+		}
+		if err := cmd.RenameDirectory(os.Args[2], os.Args[3]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "promote":
+		var args []string
+		opts := core.PromoteOptions{}
+		for _, arg := range os.Args[2:] {
+			switch arg {
+			case "--keep-date":
+				opts.KeepDate = true
+			case "--git-init":
+				opts.GitInit = true
+			default:
+				args = append(args, arg)
+			}
+		}
+		if len(args) < 1 || len(args) > 2 {
+			fmt.Fprintf(os.Stderr, "Error: usage: try promote <name> [dest] [--keep-date] [--git-init]\n")
+			os.Exit(1)
+		}
+		if len(args) == 2 {
+			opts.Dest = args[1]
+		}
+		if err := cmd.PromoteDirectory(args[0], opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	default:
-		if strings.HasPrefix(command, "http://") || strings.HasPrefix(command, "https://") || 
-		   strings.HasPrefix(command, "git@") || strings.HasSuffix(command, ".git") {
-			if err := cmd.CloneRepository(command); err != nil {
+		if core.IsGitURL(command) {
+			source, opts, err := core.ParseCloneArgs(os.Args[1:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := cmd.CloneRepository(source, opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
 		} else {
-			query := strings.Join(os.Args[1:], " ")
-			if err := cmd.RunInteractiveSelector(query); err != nil {
+			query, opts, err := cmd.ParseSelectorArgs(os.Args[1:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := cmd.RunInteractiveSelector(query, opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -111,11 +211,59 @@ func showHelp() {
 USAGE:
     try                     Open interactive directory selector
     try [query]             Search for directories matching query
+                            Options: --inline, --height <lines>,
+                              --print (write the path to stdout)
     try new [name]          Create new dated directory
+                            Options: --template <name>,
+                              --lang go|rust|node|python, --commit
+    try templates           List templates in ~/.config/try/templates
     try . [name]            Create worktree for current repository
     try clone <url>         Clone git repository with dated name
-    try worktree <path>     Create worktree from repository
-    try init [path]         Generate shell integration script
+                            Accepts URLs, gh:user/repo, gl:group/repo,
+                            user/repo and local paths. Options:
+                              --depth <n>, --full, --filter <spec>,
+                              --branch <name>, --recurse-submodules,
+                              --sparse <path,...>, --no-sparse,
+                              --owner, --cache
+    try cache list          Show cached repository mirrors
+    try cache update [url]  Fetch into cached mirrors (or add a mirror)
+    try cache gc            Remove mirrors unused for cache.max-age days
+    try hooks allow <name>  Let a try's own .try/hooks run (deny to revoke)
+    try worktree <path|try> Create worktree from repository
+    try detach-worktree <name>
+                            Turn a worktree into a standalone repository
+    try promote <name> [dest]
+                            Move a try to a permanent location (default:
+                            next to the tries folder, date prefix removed)
+                            Options: --keep-date, --git-init
+    try fork <name> [new-name]
+                            Copy a try into a new dated try, copy-on-write
+                            where the filesystem supports it
+                            Options: --skip-artifacts (node_modules, target,
+                              ... or fork.artifacts), --worktree (new
+                              branch from HEAD instead of a copy)
+    try rename <name> <new-name>
+                            Rename a try, keeping its date prefix unless
+                            new-name starts with one
+    try open <query>        Open a try in a tmux session or zellij tab,
+                            reusing it if it's already running
+                            Options: --tmux, --zellij
+    try open <query>... --with <editor>
+                            Open tries in an editor: editor ($VISUAL/
+                              $EDITOR), code, idea, ... or open.<name>
+                              from the config; --cd also cds there
+    try exec <query> -- <command...>
+                            Run a command inside a try, passing through
+                            its exit code
+    try exec --all [--filter <expr>] [-j <jobs>] -- <command...>
+                            Run a command in many tries at once, e.g.
+                              --filter 'is:git -is:dirty'
+    try completion <shell>  Print tab completion for bash, zsh or fish
+                            (already part of try init)
+    try init [path]         Generate shell integration script (path
+                            defaults to the path config key)
+                            Options: --shell bash|zsh|fish|powershell|nu|
+                              elvish|xonsh, --cmd <function name>
     try --help              Show this help message
 
 SHORTCUTS:
@@ -125,12 +273,23 @@ SHORTCUTS:
 
 ENVIRONMENT:
     TRY_PATH               Override default directory location
-                          (default: ~/src/tries)
+                          (default: path config key, then ~/src/tries)
+    TRY_CONFIG             Config file location
+                          (default: ~/.config/try/config)
+    TRY_SKIP_HOOKS         Skip on-create/on-enter/on-delete hooks in
+                          ~/.config/try/hooks
+    TRY_BINARY             try binary the shell integration runs
+                          (default: try on PATH)
+    TRY_SUBSHELL           Set to the try's path in shells try starts
+                          when the shell integration isn't loaded
+    NO_COLOR               Draw the selector without colors, whatever
+                          the theme config key says
 
 EXAMPLES:
     try                    # Open interactive selector
     try redis              # Search for "redis" directories
     try new experiment     # Create ~/src/tries/2025-08-30-experiment
+    try new api --lang go --commit
     try clone https://github.com/user/repo.git
     try . feature-branch   # Create worktree from current repo
 
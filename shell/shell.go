@@ -2,100 +2,487 @@ package shell
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
+	"text/template"
 )
 
+// WrapperVersion identifies the generated scripts. Wrappers pass it to the
+// binary as TRY_WRAPPER so a binary upgraded underneath a running shell can
+// tell that the shell is still using an older wrapper. Bump it whenever the
+// protocol between the two changes.
+const WrapperVersion = 2
+
+// Options controls the generated shell integration
+type Options struct {
+	Shell   string // Shell name, e.g. "zsh" or "pwsh"
+	TryPath string // Directory holding the tries; left to the config when empty
+	Binary  string // Pinned try binary, only needed when try isn't on PATH
+	Command string // Name of the shell function, DefaultCommand(shell) if empty
+}
+
+// scriptData is what the templates see
+type scriptData struct {
+	Options
+	Version int
+}
+
+// Shells lists the supported shells as accepted by `try init --shell`
+var Shells = []string{"bash", "zsh", "fish", "powershell", "nu", "elvish", "xonsh"}
+
+// shellAliases maps other names of a shell, such as its binary, to the
+// name used in Shells
+var shellAliases = map[string]string{
+	"sh":      "bash",
+	"pwsh":    "powershell",
+	"nushell": "nu",
+}
+
+// reservedTry lists the shells where `try` is a keyword or builtin and
+// can't be used as the function name
+var reservedTry = map[string]bool{
+	"powershell": true,
+	"nu":         true,
+	"elvish":     true,
+	"xonsh":      true,
+}
+
+// NormalizeShell turns a shell name or path, e.g. /usr/bin/pwsh, into one
+// of Shells and reports whether it is supported
+func NormalizeShell(name string) (string, bool) {
+	// $SHELL may hold a Windows path, whatever platform try was built for
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(strings.TrimSuffix(name, ".exe"))
+	if alias, ok := shellAliases[name]; ok {
+		name = alias
+	}
+
+	for _, shell := range Shells {
+		if shell == name {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+// DefaultCommand returns the function name used when none is given: "try",
+// or "tri" in shells that reserve "try" for exception handling
+func DefaultCommand(shell string) string {
+	if reservedTry[shell] {
+		return "tri"
+	}
+	return "try"
+}
+
+// GenerateShellScript returns the integration for the shell named by
+// shellName, falling back to bash for shells that aren't supported
 func GenerateShellScript(shellName string, tryPath string) string {
-	shellName = filepath.Base(shellName)
-	
-	switch {
-	case strings.Contains(shellName, "zsh"):
-		return generateZshScript(tryPath)
-	case strings.Contains(shellName, "fish"):
-		return generateFishScript(tryPath)
-	default:
-		return generateBashScript(tryPath)
+	shell, ok := NormalizeShell(shellName)
+	if !ok {
+		shell = "bash"
 	}
+
+	script, _ := Generate(Options{Shell: shell, TryPath: tryPath})
+	return script
 }
 
-func generateBashScript(tryPath string) string {
-	return fmt.Sprintf(`# Try shell integration for Bash
-export TRY_PATH="%s"
-export TRY_BINARY="%s"
+// Generate returns the shell integration described by opts. Every shell
+// gets a function that looks up the binary on PATH when it runs, points
+// TRY_CD_FILE at a fresh temporary file and changes into the directory
+// written there.
+func Generate(opts Options) (string, error) {
+	shell, ok := NormalizeShell(opts.Shell)
+	if !ok {
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", opts.Shell, strings.Join(Shells, ", "))
+	}
 
-try() {
-    # Run the try binary with its output going to the terminal
-    "${TRY_BINARY}" "$@"
-    local exit_code=$?
-    
-    # If successful, check if a .try_cd file was created with a path to cd to
-    if [ $exit_code -eq 0 ] && [ -f "$HOME/.try_cd" ]; then
-        local dir=$(cat "$HOME/.try_cd")
-        rm -f "$HOME/.try_cd"
-        if [ -d "$dir" ]; then
-            cd "$dir"
-        fi
-    fi
-    
-    return $exit_code
+	return render(shell, shell, opts)
 }
-`, tryPath, getTryBinaryPath())
+
+// CompletionShells lists the shells `try completion` supports
+var CompletionShells = []string{"bash", "zsh", "fish"}
+
+// GenerateCompletion returns the tab completion for opts.Shell on its own,
+// for use without the rest of the integration. Completions come from the
+// hidden `try __complete` command.
+func GenerateCompletion(opts Options) (string, error) {
+	shell, _ := NormalizeShell(opts.Shell)
+	if scripts.Lookup(shell+"-completion") == nil {
+		return "", fmt.Errorf("completion is not supported for %q (supported: %s)", opts.Shell, strings.Join(CompletionShells, ", "))
+	}
+
+	return render(shell, shell+"-completion", opts)
 }
 
-func generateZshScript(tryPath string) string {
-	return fmt.Sprintf(`# Try shell integration for Zsh
-export TRY_PATH="%s"
-export TRY_BINARY="%s"
+func render(shell, name string, opts Options) (string, error) {
+	opts.Shell = shell
+	if opts.Command == "" {
+		opts.Command = DefaultCommand(shell)
+	}
+
+	var buf strings.Builder
+	if err := scripts.ExecuteTemplate(&buf, name, scriptData{opts, WrapperVersion}); err != nil {
+		return "", fmt.Errorf("failed to generate %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+var scripts = template.Must(template.New("posix-function").Parse(posixFunction))
+
+func init() {
+	template.Must(scripts.New("bash").Parse(bashScript))
+	template.Must(scripts.New("zsh").Parse(zshScript))
+	template.Must(scripts.New("fish").Parse(fishScript))
+	template.Must(scripts.New("powershell").Parse(powershellScript))
+	template.Must(scripts.New("nu").Parse(nuScript))
+	template.Must(scripts.New("elvish").Parse(elvishScript))
+	template.Must(scripts.New("xonsh").Parse(xonshScript))
+	template.Must(scripts.New("bash-completion").Parse(bashCompletion))
+	template.Must(scripts.New("zsh-completion").Parse(zshCompletion))
+	template.Must(scripts.New("fish-completion").Parse(fishCompletion))
+	template.Must(scripts.New("bash-widget").Parse(bashWidget))
+	template.Must(scripts.New("zsh-widget").Parse(zshWidget))
+	template.Must(scripts.New("fish-widget").Parse(fishWidget))
+}
 
-try() {
-    # Run the try binary with its output going to the terminal
-    "${TRY_BINARY}" "$@"
+// posixFunction is the wrapper shared by bash and zsh
+const posixFunction = `{{.Command}}() {
+    # Each invocation gets its own file for the directory to cd to, so
+    # concurrent or crashed runs can't send another shell somewhere else
+    local cd_file
+    cd_file=$(mktemp -t try-cd.XXXXXX) || return 1
+
+    # Run whichever try is on PATH now, so upgrading or moving the binary
+    # doesn't need a new shell. TRY_WRAPPER tells it which wrapper called.
+    TRY_WRAPPER={{.Version}} TRY_CD_FILE="$cd_file" command "${TRY_BINARY:-try}" "$@"
     local exit_code=$?
-    
-    # If successful, check if a .try_cd file was created with a path to cd to
-    if [ $exit_code -eq 0 ] && [ -f "$HOME/.try_cd" ]; then
-        local dir=$(cat "$HOME/.try_cd")
-        rm -f "$HOME/.try_cd"
+
+    # If successful, check if try left a path to cd to
+    if [ $exit_code -eq 0 ] && [ -s "$cd_file" ]; then
+        local dir=$(cat "$cd_file")
         if [ -d "$dir" ]; then
             cd "$dir"
         fi
     fi
-    
+    rm -f "$cd_file"
+
     return $exit_code
 }
-`, tryPath, getTryBinaryPath())
-}
+`
+
+const bashScript = `# Try shell integration for Bash
+{{- if .TryPath}}
+export TRY_PATH="{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+export TRY_BINARY="{{.Binary}}"
+{{- end}}
+
+{{template "posix-function" .}}
+{{template "bash-completion" .}}
+{{template "bash-widget" .}}`
 
-func generateFishScript(tryPath string) string {
-	return fmt.Sprintf(`# Try shell integration for Fish
-set -x TRY_PATH "%s"
-set -x TRY_BINARY "%s"
+const zshScript = `# Try shell integration for Zsh
+{{- if .TryPath}}
+export TRY_PATH="{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+export TRY_BINARY="{{.Binary}}"
+{{- end}}
 
-function try
-    # Run the try binary with its output going to the terminal
-    $TRY_BINARY $argv
+{{template "posix-function" .}}
+{{template "zsh-completion" .}}
+{{template "zsh-widget" .}}`
+
+const fishScript = `# Try shell integration for Fish
+{{- if .TryPath}}
+set -x TRY_PATH "{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+set -x TRY_BINARY "{{.Binary}}"
+{{- end}}
+
+function {{.Command}}
+    # Each invocation gets its own file for the directory to cd to, so
+    # concurrent or crashed runs can't send another shell somewhere else
+    set -l cd_file (mktemp -t try-cd.XXXXXX); or return 1
+
+    # Run whichever try is on PATH now, so upgrading or moving the binary
+    # doesn't need a new shell. TRY_WRAPPER tells it which wrapper called.
+    set -l binary try
+    set -q TRY_BINARY; and set binary $TRY_BINARY
+    TRY_WRAPPER={{.Version}} TRY_CD_FILE=$cd_file command $binary $argv
     set -l exit_code $status
-    
-    # If successful, check if a .try_cd file was created with a path to cd to
-    if test $exit_code -eq 0; and test -f "$HOME/.try_cd"
-        set -l dir (cat "$HOME/.try_cd")
-        rm -f "$HOME/.try_cd"
+
+    # If successful, check if try left a path to cd to
+    if test $exit_code -eq 0; and test -s "$cd_file"
+        set -l dir (cat "$cd_file")
         if test -d "$dir"
             cd "$dir"
         end
     end
-    
+    rm -f "$cd_file"
+
     return $exit_code
 end
-`, tryPath, getTryBinaryPath())
+
+{{template "fish-completion" .}}
+{{template "fish-widget" .}}`
+
+const powershellScript = `# Try shell integration for PowerShell
+{{- if .TryPath}}
+$env:TRY_PATH = "{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+$env:TRY_BINARY = "{{.Binary}}"
+{{- end}}
+
+function {{.Command}} {
+    # Run whichever try is on PATH now, so upgrading or moving the binary
+    # doesn't need a new shell
+    $binary = $env:TRY_BINARY
+    if (-not $binary) {
+        $binary = (Get-Command try -CommandType Application -ErrorAction Stop | Select-Object -First 1).Source
+    }
+
+    # Each invocation gets its own file for the directory to cd to, so
+    # concurrent or crashed runs can't send another shell somewhere else
+    $cdFile = [System.IO.Path]::GetTempFileName()
+
+    # TRY_WRAPPER tells the binary which wrapper called
+    $env:TRY_CD_FILE = $cdFile
+    $env:TRY_WRAPPER = "{{.Version}}"
+    try {
+        & $binary @args
+        $exitCode = $LASTEXITCODE
+    } finally {
+        Remove-Item Env:TRY_CD_FILE, Env:TRY_WRAPPER -ErrorAction SilentlyContinue
+    }
+
+    # If successful, check if try left a path to cd to
+    if ($exitCode -eq 0) {
+        $dir = Get-Content -Raw -LiteralPath $cdFile
+        if ($dir -and (Test-Path -LiteralPath $dir -PathType Container)) {
+            Set-Location -LiteralPath $dir
+        }
+    }
+    Remove-Item -LiteralPath $cdFile -ErrorAction SilentlyContinue
+
+    $global:LASTEXITCODE = $exitCode
+}
+`
+
+const nuScript = `# Try shell integration for Nushell
+{{- if .TryPath}}
+$env.TRY_PATH = "{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+$env.TRY_BINARY = "{{.Binary}}"
+{{- end}}
+
+def --env --wrapped {{.Command}} [...args: string] {
+    # Each invocation gets its own file for the directory to cd to, so
+    # concurrent or crashed runs can't send another shell somewhere else
+    let cd_file = (mktemp -t try-cd.XXXXXX)
+
+    # Run whichever try is on PATH now, so upgrading or moving the binary
+    # doesn't need a new shell. TRY_WRAPPER tells it which wrapper called.
+    let binary = ($env.TRY_BINARY? | default "try")
+    try { with-env { TRY_CD_FILE: $cd_file, TRY_WRAPPER: "{{.Version}}" } { ^$binary ...$args } }
+    let exit_code = $env.LAST_EXIT_CODE
+
+    # If successful, check if try left a path to cd to
+    if $exit_code == 0 {
+        let dir = (open --raw $cd_file | str trim)
+        if $dir != "" and ($dir | path type) == "dir" {
+            cd $dir
+        }
+    }
+    rm -f $cd_file
+}
+`
+
+const elvishScript = `# Try shell integration for Elvish
+use path
+{{- if .TryPath}}
+set E:TRY_PATH = "{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+set E:TRY_BINARY = "{{.Binary}}"
+{{- end}}
+
+fn {{.Command}} {|@args|
+    # Run whichever try is on PATH now, so upgrading or moving the binary
+    # doesn't need a new shell
+    var binary = 'try'
+    if (has-env TRY_BINARY) {
+        set binary = $E:TRY_BINARY
+    }
+
+    # Each invocation gets its own file for the directory to cd to, so
+    # concurrent or crashed runs can't send another shell somewhere else
+    var cd-file = (mktemp -t try-cd.XXXXXX)
+
+    try {
+        # A failure skips the cd and is passed on once the file is cleaned
+        # up. TRY_WRAPPER tells the binary which wrapper called.
+        env TRY_CD_FILE=$cd-file TRY_WRAPPER={{.Version}} $binary $@args
+
+        var dir = (slurp < $cd-file)
+        if (and (!=s $dir '') (path:is-dir $dir)) {
+            cd $dir
+        }
+    } finally {
+        rm -f $cd-file
+    }
+}
+`
+
+const xonshScript = `# Try shell integration for Xonsh
+{{- if .TryPath}}
+$TRY_PATH = "{{.TryPath}}"
+{{- end}}
+{{- if .Binary}}
+$TRY_BINARY = "{{.Binary}}"
+{{- end}}
+
+from xonsh.tools import unthreadable
+
+@unthreadable
+def _try_wrapper(args):
+    import os
+    import shutil
+    import tempfile
+
+    # Run whichever try is on PATH now, so upgrading or moving the binary
+    # doesn't need a new shell
+    binary = ${...}.get("TRY_BINARY") or shutil.which("try") or "try"
+
+    # Each invocation gets its own file for the directory to cd to, so
+    # concurrent or crashed runs can't send another shell somewhere else
+    fd, cd_file = tempfile.mkstemp(prefix="try-cd.")
+    os.close(fd)
+
+    try:
+        # TRY_WRAPPER tells the binary which wrapper called
+        with ${...}.swap(TRY_CD_FILE=cd_file, TRY_WRAPPER="{{.Version}}"):
+            result = ![@(binary) @(args)]
+
+        # If successful, check if try left a path to cd to
+        if result.returncode == 0:
+            with open(cd_file) as f:
+                target = f.read().strip()
+            if target and os.path.isdir(target):
+                cd @(target)
+        return result.returncode
+    finally:
+        os.remove(cd_file)
+
+aliases["{{.Command}}"] = _try_wrapper
+`
+
+const bashCompletion = `# Tab completion for subcommands, flags and try names
+_{{.Command}}_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(command "${TRY_BINARY:-try}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+
+    # Keep completing inside a directory instead of ending the word
+    if [ ${#COMPREPLY[@]} -eq 1 ] && [[ ${COMPREPLY[0]} == */ ]]; then
+        compopt -o nospace 2>/dev/null
+    fi
+}
+complete -F _{{.Command}}_complete {{.Command}}
+`
+
+const zshCompletion = `# Tab completion for subcommands, flags and try names
+_{{.Command}}_complete() {
+    local -a candidates
+    candidates=(${(f)"$(command "${TRY_BINARY:-try}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+
+    # Try names match anywhere, not just at the start, so don't let zsh
+    # filter them by prefix
+    compadd -U -- $candidates
+}
+if (( $+functions[compdef] )); then
+    compdef _{{.Command}}_complete {{.Command}}
+fi
+`
+
+const fishCompletion = `# Tab completion for subcommands, flags and try names
+function __{{.Command}}_complete
+    set -l binary try
+    set -q TRY_BINARY; and set binary $TRY_BINARY
+
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    command $binary __complete $tokens[2..-1] "$current" 2>/dev/null
+end
+complete -c {{.Command}} -f -a '(__{{.Command}}_complete)'
+`
+
+// The widgets open the selector inline below the prompt. On an empty command
+// line they cd to the chosen try, otherwise they insert its path at the
+// cursor.
+
+const bashWidget = `# Alt+T: jump to a try, or insert its path into the command line
+__{{.Command}}_widget() {
+    local dir
+    dir=$(command "${TRY_BINARY:-try}" --print --inline --height 15) || return
+    [ -n "$dir" ] || return
+
+    if [ -z "${READLINE_LINE//[[:space:]]/}" ]; then
+        builtin cd -- "$dir"
+    else
+        local quoted
+        quoted=$(printf '%q' "$dir")
+        READLINE_LINE="${READLINE_LINE:0:READLINE_POINT}${quoted}${READLINE_LINE:READLINE_POINT}"
+        READLINE_POINT=$((READLINE_POINT + ${#quoted}))
+    fi
 }
+if [[ $- == *i* ]]; then
+    bind -x '"\et": __{{.Command}}_widget'
+fi
+`
 
-func getTryBinaryPath() string {
-	// Try to find the try binary in PATH, otherwise use the command name
-	if execPath, err := os.Executable(); err == nil {
-		return execPath
-	}
-	return "try"
-}
\ No newline at end of file
+const zshWidget = `# Alt+T: jump to a try, or insert its path into the command line
+__{{.Command}}_widget() {
+    local dir
+    dir=$(command "${TRY_BINARY:-try}" --print --inline --height 15 < /dev/tty)
+
+    if [[ -n $dir ]]; then
+        if [[ -z ${BUFFER//[[:space:]]/} ]]; then
+            builtin cd -- "$dir"
+            # Let prompt themes notice the new directory
+            local precmd
+            for precmd in $precmd_functions; do
+                $precmd
+            done
+        else
+            LBUFFER+=${(q)dir}
+        fi
+    fi
+    zle reset-prompt
+}
+zle -N __{{.Command}}_widget
+bindkey '\et' __{{.Command}}_widget
+`
+
+const fishWidget = `# Alt+T: jump to a try, or insert its path into the command line
+function __{{.Command}}_widget
+    set -l binary try
+    set -q TRY_BINARY; and set binary $TRY_BINARY
+    set -l dir (command $binary --print --inline --height 15)
+
+    if test -n "$dir"
+        if test -z (string trim -- (commandline))
+            cd $dir
+        else
+            commandline -i -- (string escape -- $dir)
+        end
+    end
+    commandline -f repaint
+end
+bind \et __{{.Command}}_widget
+bind -M insert \et __{{.Command}}_widget 2>/dev/null
+`
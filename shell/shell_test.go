@@ -0,0 +1,125 @@
+package shell
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestGenerateGolden(t *testing.T) {
+	for _, shell := range Shells {
+		t.Run(shell, func(t *testing.T) {
+			script, err := Generate(Options{
+				Shell:   shell,
+				TryPath: "/home/user/src/tries",
+				Binary:  "/usr/local/bin/try",
+			})
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			golden := filepath.Join("testdata", shell+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(script), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run go test ./shell -update): %v", err)
+			}
+			if script != string(want) {
+				t.Errorf("generated script differs from %s:\n%s", golden, script)
+			}
+		})
+	}
+}
+
+func TestNormalizeShell(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"/bin/zsh", "zsh", true},
+		{"/usr/local/bin/fish", "fish", true},
+		{"pwsh", "powershell", true},
+		{"C:\\Program Files\\PowerShell\\7\\pwsh.exe", "powershell", true},
+		{"nushell", "nu", true},
+		{"/usr/bin/elvish", "elvish", true},
+		{"tcsh", "tcsh", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeShell(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeShell(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGenerateCommandName(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Shell: "bash"}, "try() {"},
+		{Options{Shell: "bash", Command: "t"}, "t() {"},
+		{Options{Shell: "pwsh"}, "function tri {"},
+		{Options{Shell: "nu"}, "def --env --wrapped tri "},
+		{Options{Shell: "elvish", Command: "t"}, "fn t {"},
+		{Options{Shell: "xonsh"}, `aliases["tri"]`},
+	}
+
+	for _, tt := range tests {
+		script, err := Generate(tt.opts)
+		if err != nil {
+			t.Fatalf("Generate(%+v) failed: %v", tt.opts, err)
+		}
+		if !strings.Contains(script, tt.want) {
+			t.Errorf("Generate(%+v) doesn't define %q", tt.opts, tt.want)
+		}
+	}
+
+	if _, err := Generate(Options{Shell: "tcsh"}); err == nil {
+		t.Error("Generate(tcsh) succeeded, want error")
+	}
+}
+
+func TestGenerateCompletion(t *testing.T) {
+	for _, shell := range CompletionShells {
+		script, err := GenerateCompletion(Options{Shell: shell, Command: "t"})
+		if err != nil {
+			t.Fatalf("GenerateCompletion(%s) failed: %v", shell, err)
+		}
+		if !strings.Contains(script, "__complete") || !strings.Contains(script, "_t_complete") {
+			t.Errorf("GenerateCompletion(%s) doesn't complete t through __complete:\n%s", shell, script)
+		}
+	}
+
+	if _, err := GenerateCompletion(Options{Shell: "nu"}); err == nil {
+		t.Error("GenerateCompletion(nu) succeeded, want error")
+	}
+}
+
+func TestGenerateRelocatable(t *testing.T) {
+	for _, shell := range Shells {
+		script, err := Generate(Options{Shell: shell})
+		if err != nil {
+			t.Fatalf("Generate(%s) failed: %v", shell, err)
+		}
+		for _, pinned := range []string{"TRY_PATH", "/usr/local/bin"} {
+			if strings.Contains(script, pinned) {
+				t.Errorf("Generate(%s) pins %s although no path or binary was given", shell, pinned)
+			}
+		}
+		if !strings.Contains(script, "TRY_WRAPPER") {
+			t.Errorf("Generate(%s) doesn't pass TRY_WRAPPER to the binary", shell)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/zengjie/try/core"
+)
+
+// Column is an optional column of the selector list. The name is always
+// shown and gets whatever width the other columns leave.
+type Column string
+
+const (
+	ColumnTags   Column = "tags"   // Kind of try and its tags
+	ColumnAge    Column = "age"    // Time of what the list is sorted by, modified otherwise
+	ColumnSize   Column = "size"   // Disk usage, measured in the background
+	ColumnBranch Column = "branch" // Checked out branch of repositories and worktrees
+	ColumnScore  Column = "score"  // Relevance to the search
+)
+
+// Columns lists every column in the order they are documented
+var Columns = []Column{ColumnTags, ColumnAge, ColumnSize, ColumnBranch, ColumnScore}
+
+// DefaultColumns are shown when the config doesn't set columns
+var DefaultColumns = []Column{ColumnTags, ColumnAge}
+
+// columnWidths are the fixed widths of the optional columns
+var columnWidths = map[Column]int{
+	ColumnTags:   15,
+	ColumnAge:    15,
+	ColumnSize:   9,
+	ColumnBranch: 16,
+	ColumnScore:  5,
+}
+
+const (
+	// minNameWidth is the narrowest the name column gets before columns on
+	// the right are dropped to make room
+	minNameWidth = 20
+	// rowPrefixWidth is taken by the cursor and the mark
+	rowPrefixWidth = 2
+)
+
+// ParseColumns checks a list of column names
+func ParseColumns(names []string) ([]Column, error) {
+	var columns []Column
+	for _, name := range names {
+		column := Column(strings.ToLower(name))
+		if !slices.Contains(Columns, column) {
+			valid := make([]string, len(Columns))
+			for i, c := range Columns {
+				valid[i] = string(c)
+			}
+			return nil, fmt.Errorf("unknown column %q (use %s)", name, strings.Join(valid, ", "))
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// LoadColumns returns the columns listed by the columns config key, in
+// their order, or DefaultColumns. An empty list shows only names.
+func LoadColumns() ([]Column, error) {
+	config := core.GetConfig()
+	if _, ok := config.Get("columns"); !ok {
+		return DefaultColumns, nil
+	}
+	return ParseColumns(config.List("columns"))
+}
+
+// columnLayout is how a row is split at a given terminal width
+type columnLayout struct {
+	name    int
+	columns []Column
+}
+
+// layoutColumns fits the name and columns into width. Sorting by size shows
+// the size column even when it isn't configured. Columns are dropped from the
+// right while the name would get narrower than minNameWidth.
+func layoutColumns(width int, columns []Column, sortMode core.SortMode) columnLayout {
+	if sortMode == core.SortSize && !slices.Contains(columns, ColumnSize) {
+		columns = append(slices.Clone(columns), ColumnSize)
+	}
+
+	for {
+		name := width - rowPrefixWidth
+		for _, column := range columns {
+			name -= columnWidths[column] + 1
+		}
+		if name >= minNameWidth || len(columns) == 0 {
+			return columnLayout{name: max(name, 1), columns: columns}
+		}
+		columns = columns[:len(columns)-1]
+	}
+}
+
+// rowWidth is the width of the name and columns together
+func (l columnLayout) rowWidth() int {
+	width := l.name
+	for _, column := range l.columns {
+		width += columnWidths[column] + 1
+	}
+	return width
+}
+
+// header renders the column titles, lined up with the rows
+func (l columnLayout) header(sortMode core.SortMode) string {
+	cells := []string{fitWidth("Name", l.name)}
+	for _, column := range l.columns {
+		cells = append(cells, l.cell(column, columnTitle(column, sortMode)))
+	}
+	return strings.Repeat(" ", rowPrefixWidth) + strings.Join(cells, " ")
+}
+
+// row renders the name and columns of a try
+func (l columnLayout) row(dir core.Directory, sortMode core.SortMode) string {
+	cells := []string{fitWidth(truncateMiddle(dir.Name, l.name), l.name)}
+	for _, column := range l.columns {
+		cells = append(cells, l.cell(column, columnValue(column, dir, sortMode)))
+	}
+	return strings.Join(cells, " ")
+}
+
+// cell fits value into the width of column, numbers aligned to the right
+func (l columnLayout) cell(column Column, value string) string {
+	width := columnWidths[column]
+	value = ansi.Truncate(value, width, "…")
+	if column == ColumnSize || column == ColumnScore {
+		return strings.Repeat(" ", width-ansi.StringWidth(value)) + value
+	}
+	return fitWidth(value, width)
+}
+
+func columnTitle(column Column, sortMode core.SortMode) string {
+	switch column {
+	case ColumnTags:
+		return "Tags"
+	case ColumnAge:
+		return lastColumnTitle(sortMode)
+	case ColumnSize:
+		return "Size"
+	case ColumnBranch:
+		return "Branch"
+	case ColumnScore:
+		return "Score"
+	}
+	return ""
+}
+
+func columnValue(column Column, dir core.Directory, sortMode core.SortMode) string {
+	switch column {
+	case ColumnTags:
+		var tags []string
+		if dir.IsGitRepo {
+			tags = append(tags, "git")
+		}
+		if dir.IsWorktree {
+			tags = append(tags, "worktree")
+		}
+		if dir.IsAlias {
+			tags = append(tags, "promoted")
+		}
+		for _, tag := range dir.Tags {
+			tags = append(tags, "#"+tag)
+		}
+		if len(tags) == 0 {
+			return "-"
+		}
+		return strings.Join(tags, " ")
+	case ColumnAge:
+		switch sortMode {
+		case core.SortCreated:
+			return core.GetRelativeDate(dir.CreatedTime)
+		case core.SortVisited:
+			if dir.VisitedTime.IsZero() {
+				return "never"
+			}
+			return core.GetRelativeAge(dir.VisitedTime)
+		}
+		return core.GetRelativeAge(dir.ModifiedTime)
+	case ColumnSize:
+		// Sizes arrive in the background, until then it is unknown
+		if dir.Size < 0 {
+			return "..."
+		}
+		return core.FormatSize(dir.Size)
+	case ColumnBranch:
+		if dir.Branch == "" {
+			return "-"
+		}
+		return dir.Branch
+	case ColumnScore:
+		return fmt.Sprintf("%.2f", dir.Score)
+	}
+	return ""
+}
+
+// truncateMiddle shortens s to width terminal cells by cutting out its
+// middle, so both the date and the end of a long name stay visible
+func truncateMiddle(s string, width int) string {
+	if ansi.StringWidth(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return ansi.Truncate(s, width, "")
+	}
+
+	tail := (width - 1) / 2
+	head := width - 1 - tail
+	// A wide character across the cut is kept whole, so cut further
+	end := s
+	for cut := ansi.StringWidth(s) - tail; ansi.StringWidth(end) > tail; cut++ {
+		end = ansi.TruncateLeft(s, cut, "")
+	}
+	return ansi.Truncate(s, head, "") + "…" + end
+}
+
+// fitWidth truncates or pads s to exactly width terminal cells
+func fitWidth(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/zengjie/try/core"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"2025-08-30-redis", 20, "2025-08-30-redis"},
+		{"2025-08-30-redis", 16, "2025-08-30-redis"},
+		{"2025-08-30-a-very-long-name", 10, "2025-…name"},
+		{"2025-08-30-a-very-long-name", 0, ""},
+		{"2025-08-30-a-very-long-name", 1, "2"},
+		{"2025-08-30-a-very-long-name", 2, "2…"},
+		{"2025-08-30-a-very-long-name", 3, "2…e"},
+		{"2025-08-30-café-crème-brûlée", 10, "2025-…ûlée"},
+		{"2025-08-30-café-crème-brûlée", 7, "202…lée"},
+		{"日本語のプロジェクト", 12, "日本語…クト"},
+		{"日本語のプロジェクト", 9, "日本…クト"},
+		{"日本語のプロジェクト", 2, "…"},
+		{"日本語のプロジェクト", 1, ""},
+		{"2025-08-30-🚀-rocket-launch", 10, "2025-…unch"},
+		// A wide character that doesn't fit on either side of the cut is
+		// left out rather than overflowing
+		{"abcd🚀e", 5, "ab…e"},
+		{"a🚀bcdef", 5, "a…ef"},
+	}
+
+	for _, tt := range tests {
+		got := truncateMiddle(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+
+	// Whatever gets cut, the result never overflows
+	for _, s := range []string{"日本語のプロジェクト", "🚀a🚀b🚀c🚀d🚀", "2025-08-30-café-crème-brûlée"} {
+		for width := 0; width <= ansi.StringWidth(s)+1; width++ {
+			if got := truncateMiddle(s, width); ansi.StringWidth(got) > width {
+				t.Errorf("truncateMiddle(%q, %d) = %q is %d cells wide", s, width, got, ansi.StringWidth(got))
+			}
+		}
+	}
+}
+
+func TestLayoutColumns(t *testing.T) {
+	all := []Column{ColumnTags, ColumnAge, ColumnSize, ColumnBranch, ColumnScore}
+
+	tests := []struct {
+		name     string
+		width    int
+		columns  []Column
+		sortMode core.SortMode
+		want     columnLayout
+	}{
+		{"everything fits", 120, DefaultColumns, core.SortScore, columnLayout{86, []Column{ColumnTags, ColumnAge}}},
+		{"no columns", 80, nil, core.SortScore, columnLayout{78, nil}},
+		{"drops from the right", 60, all, core.SortScore, columnLayout{26, []Column{ColumnTags, ColumnAge}}},
+		{"keeps the name readable", 40, DefaultColumns, core.SortScore, columnLayout{22, []Column{ColumnTags}}},
+		{"too narrow for any column", 10, DefaultColumns, core.SortScore, columnLayout{8, []Column{}}},
+		{"never below one cell", 1, nil, core.SortScore, columnLayout{1, nil}},
+		{"size when sorting by size", 120, DefaultColumns, core.SortSize, columnLayout{76, []Column{ColumnTags, ColumnAge, ColumnSize}}},
+		{"size shown once", 120, []Column{ColumnSize}, core.SortSize, columnLayout{108, []Column{ColumnSize}}},
+		{"added size is dropped first", 50, DefaultColumns, core.SortSize, columnLayout{32, []Column{ColumnTags}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := layoutColumns(tt.width, tt.columns, tt.sortMode)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layoutColumns(%d, %v, %s) = %+v, want %+v", tt.width, tt.columns, tt.sortMode, got, tt.want)
+			}
+		})
+	}
+
+	if !slices.Equal(DefaultColumns, []Column{ColumnTags, ColumnAge}) {
+		t.Errorf("layoutColumns changed DefaultColumns to %v", DefaultColumns)
+	}
+}
+
+func TestColumnLayoutRow(t *testing.T) {
+	layout := layoutColumns(80, []Column{ColumnTags, ColumnSize, ColumnBranch, ColumnScore}, core.SortScore)
+	dir := core.Directory{Name: "2025-08-30-日本語のプロジェクト-with-a-long-name", IsGitRepo: true, Size: -1, Score: 0.5}
+
+	row := layout.row(dir, core.SortScore)
+	if width := ansi.StringWidth(row); width != layout.rowWidth() {
+		t.Errorf("row %q is %d cells wide, want %d", row, width, layout.rowWidth())
+	}
+	if width := ansi.StringWidth(layout.header(core.SortScore)); width != layout.rowWidth()+rowPrefixWidth {
+		t.Errorf("header is %d cells wide, want %d", width, layout.rowWidth()+rowPrefixWidth)
+	}
+
+	for column, want := range map[Column]string{ColumnTags: "git", ColumnSize: "...", ColumnBranch: "-", ColumnScore: "0.50"} {
+		if got := columnValue(column, dir, core.SortScore); got != want {
+			t.Errorf("%s column = %q, want %q", column, got, want)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	got, err := ParseColumns([]string{"Branch", "tags", "branch"})
+	if want := []Column{ColumnBranch, ColumnTags}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseColumns = %v, %v, want %v", got, err, want)
+	}
+	if _, err := ParseColumns([]string{"tags", "owner"}); err == nil {
+		t.Error("ParseColumns with an unknown column succeeded, want error")
+	}
+}
@@ -2,7 +2,10 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // HelpContent centralizes all help information
@@ -19,45 +22,63 @@ type HelpItem struct {
 	Description string
 }
 
-// GetHelpContent returns the centralized help information
-func GetHelpContent() HelpContent {
-	return HelpContent{
-		Navigation: []HelpItem{
-			{"↑/↓", "Move up/down"},
-			{"←/→", "Page up/down"},
-			{"PgUp/PgDn", "Page up/down"},
-			{"Home/End", "Go to top/bottom"},
-		},
-		Actions: []HelpItem{
-			{"Enter", "Select/Create directory"},
-			{"ESC", "Clear search/Cancel"},
-			{"Tab", "Auto-complete search"},
-			{"Ctrl+N", "Create new directory"},
-			{"Ctrl+D", "Delete directory"},
-			{"Ctrl+W", "Create worktree (git repos)"},
-			{"Ctrl+G", "Clone git repository"},
-			{"Ctrl+R", "Initialize git repository"},
-		},
+// GetHelpContent returns the centralized help information, with the keys
+// taken from the bindings so the help can't drift from them
+func GetHelpContent(keys KeyMap) HelpContent {
+	help := HelpContent{
 		Search: []HelpItem{
 			{"Type", "Filter directories"},
-			{"Backspace", "Delete character"},
-			{"Ctrl+U", "Clear search"},
-		},
-		Other: []HelpItem{
-			{"?", "Show help"},
-			{"q, Ctrl+C", "Quit"},
 		},
 		Tips: []string{
-			"Directories are sorted by relevance when searching",
+			"Directories are sorted by relevance when searching and sorting by score",
 			"New directories get today's date prefix automatically",
 			"Git repositories and worktrees have special indicators",
+			"Actions apply to every marked directory, or the selected one",
+			"Rebind keys with keys.<action> or keys.preset: vim|emacs in the config",
 		},
 	}
+	if marks := keys.Mark.Keys(); slices.Contains(marks, " ") {
+		tip := "Space marks while the search is empty and types a space otherwise"
+		if others := slices.DeleteFunc(slices.Clone(marks), func(k string) bool { return k == " " }); len(others) > 0 {
+			tip += ", " + keysLabel(others) + " marks at any time"
+		}
+		help.Tips = append(help.Tips, tip)
+	}
+	
+	for _, action := range keys.keyActions() {
+		if !action.binding.Enabled() {
+			continue
+		}
+		item := HelpItem{action.binding.Help().Key, action.binding.Help().Desc}
+		switch action.section {
+		case sectionNavigation:
+			help.Navigation = append(help.Navigation, item)
+		case sectionActions:
+			help.Actions = append(help.Actions, item)
+		case sectionSearch:
+			help.Search = append(help.Search, item)
+		case sectionOther:
+			help.Other = append(help.Other, item)
+		}
+	}
+	return help
+}
+
+// keyColumnWidth fits the widest key label of items
+func keyColumnWidth(items ...[]HelpItem) int {
+	width := 12
+	for _, section := range items {
+		for _, item := range section {
+			width = max(width, lipgloss.Width(item.Key))
+		}
+	}
+	return width
 }
 
 // RenderInteractiveHelp generates the formatted help screen for interactive mode
 func (m Model) RenderInteractiveHelp() string {
-	help := GetHelpContent()
+	help := GetHelpContent(m.keys)
+	width := keyColumnWidth(help.Navigation, help.Actions, help.Search, help.Other)
 	
 	var sections []string
 	sections = append(sections, "🚀 Try - Keyboard Shortcuts")
@@ -66,28 +87,28 @@ func (m Model) RenderInteractiveHelp() string {
 	// Navigation section
 	sections = append(sections, "Navigation:")
 	for _, item := range help.Navigation {
-		sections = append(sections, fmt.Sprintf("  %-12s %s", item.Key, item.Description))
+		sections = append(sections, fmt.Sprintf("  %s %s", padKey(item.Key, width), item.Description))
 	}
 	sections = append(sections, "")
 	
 	// Actions section
 	sections = append(sections, "Actions:")
 	for _, item := range help.Actions {
-		sections = append(sections, fmt.Sprintf("  %-12s %s", item.Key, item.Description))
+		sections = append(sections, fmt.Sprintf("  %s %s", padKey(item.Key, width), item.Description))
 	}
 	sections = append(sections, "")
 	
 	// Search section
 	sections = append(sections, "Search:")
 	for _, item := range help.Search {
-		sections = append(sections, fmt.Sprintf("  %-12s %s", item.Key, item.Description))
+		sections = append(sections, fmt.Sprintf("  %s %s", padKey(item.Key, width), item.Description))
 	}
 	sections = append(sections, "")
 	
 	// Other section
 	sections = append(sections, "Other:")
 	for _, item := range help.Other {
-		sections = append(sections, fmt.Sprintf("  %-12s %s", item.Key, item.Description))
+		sections = append(sections, fmt.Sprintf("  %s %s", padKey(item.Key, width), item.Description))
 	}
 	sections = append(sections, "")
 	
@@ -100,9 +121,14 @@ func (m Model) RenderInteractiveHelp() string {
 	return helpViewStyle.Render(strings.Join(sections, "\n"))
 }
 
-// RenderCLIKeyboardShortcuts generates the keyboard shortcuts section for CLI help
+// RenderCLIKeyboardShortcuts generates the keyboard shortcuts section for CLI
+// help, showing the keys from the config
 func RenderCLIKeyboardShortcuts() string {
-	help := GetHelpContent()
+	keys, err := LoadKeyMap()
+	if err != nil {
+		keys = DefaultKeyMap()
+	}
+	help := GetHelpContent(keys)
 	
 	var lines []string
 	lines = append(lines, "KEYBOARD SHORTCUTS (Interactive Mode):")
@@ -113,11 +139,18 @@ func RenderCLIKeyboardShortcuts() string {
 	allItems = append(allItems, help.Actions...)
 	allItems = append(allItems, help.Search...)
 	allItems = append(allItems, help.Other...)
+	width := keyColumnWidth(allItems)
 	
 	// Format for CLI (with consistent spacing)
 	for _, item := range allItems {
-		lines = append(lines, fmt.Sprintf("    %-15s %s", item.Key, item.Description))
+		lines = append(lines, fmt.Sprintf("    %s %s", padKey(item.Key, max(width, 15)), item.Description))
 	}
 	
 	return strings.Join(lines, "\n")
-}
\ No newline at end of file
+}
+
+// padKey pads a key label to width cells, which %-*s would miscount for
+// arrows
+func padKey(label string, width int) string {
+	return label + strings.Repeat(" ", max(width-lipgloss.Width(label), 0))
+}
@@ -0,0 +1,301 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/zengjie/try/core"
+)
+
+// KeyMap holds the selector's key bindings. Each one is an action that can
+// be bound to other keys with keys.<action> in the config, see keyActions.
+type KeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Home     key.Binding
+	End      key.Binding
+
+	Select       key.Binding
+	Cancel       key.Binding
+	Complete     key.Binding
+	Session      key.Binding
+	Open         key.Binding
+	ChooseEditor key.Binding
+	Create       key.Binding
+	Mark         key.Binding
+	MarkAll      key.Binding
+	Actions      key.Binding
+	Delete       key.Binding
+	Rename       key.Binding
+	Fork         key.Binding
+	Worktree     key.Binding
+	Clone        key.Binding
+	GitInit      key.Binding
+	Sort         key.Binding
+	Group        key.Binding
+
+	DeleteChar  key.Binding
+	ClearSearch key.Binding
+
+	Help key.Binding
+	Quit key.Binding
+}
+
+// keyAction ties a binding to its config name and its place in the help
+type keyAction struct {
+	name        string
+	section     string
+	description string
+	binding     *key.Binding
+}
+
+// Help sections, in the order the help screen shows them
+const (
+	sectionNavigation = "Navigation"
+	sectionActions    = "Actions"
+	sectionSearch     = "Search"
+	sectionOther      = "Other"
+)
+
+// keyActions lists every binding in k in help order
+func (k *KeyMap) keyActions() []keyAction {
+	return []keyAction{
+		{"up", sectionNavigation, "Move up", &k.Up},
+		{"down", sectionNavigation, "Move down", &k.Down},
+		{"page-up", sectionNavigation, "Page up", &k.PageUp},
+		{"page-down", sectionNavigation, "Page down", &k.PageDown},
+		{"home", sectionNavigation, "Go to top", &k.Home},
+		{"end", sectionNavigation, "Go to bottom", &k.End},
+
+		{"select", sectionActions, "Select/Create directory", &k.Select},
+		{"cancel", sectionActions, "Clear search/Cancel", &k.Cancel},
+		{"complete", sectionActions, "Auto-complete search", &k.Complete},
+		{"session", sectionActions, "Open in tmux/zellij session", &k.Session},
+		{"open", sectionActions, "Open in editor (open.default)", &k.Open},
+		{"choose-editor", sectionActions, "Choose editor to open in", &k.ChooseEditor},
+		{"create", sectionActions, "Create new directory", &k.Create},
+		{"mark", sectionActions, "Mark/unmark directory", &k.Mark},
+		{"mark-all", sectionActions, "Mark/unmark all visible", &k.MarkAll},
+		{"actions", sectionActions, "Trash, archive, tag or fetch marked", &k.Actions},
+		{"delete", sectionActions, "Delete directory (or all marked)", &k.Delete},
+		{"rename", sectionActions, "Rename directory", &k.Rename},
+		{"fork", sectionActions, "Fork (copy) directory", &k.Fork},
+		{"worktree", sectionActions, "Create worktree (git repos)", &k.Worktree},
+		{"clone", sectionActions, "Clone git repository", &k.Clone},
+		{"git-init", sectionActions, "Initialize git repository", &k.GitInit},
+		{"sort", sectionActions, "Change sort order", &k.Sort},
+		{"group", sectionActions, "Change grouping", &k.Group},
+
+		{"delete-char", sectionSearch, "Delete character", &k.DeleteChar},
+		{"clear-search", sectionSearch, "Clear search", &k.ClearSearch},
+
+		{"help", sectionOther, "Show help", &k.Help},
+		{"quit", sectionOther, "Quit", &k.Quit},
+	}
+}
+
+// defaultKeys are the keys of every action without a preset or config
+var defaultKeys = map[string][]string{
+	"up":            {"up", "ctrl+k"},
+	"down":          {"down", "ctrl+j"},
+	"page-up":       {"left", "pgup", "ctrl+p"},
+	"page-down":     {"right", "pgdown", "ctrl+f"},
+	"home":          {"home"},
+	"end":           {"end"},
+	"select":        {"enter"},
+	"cancel":        {"esc"},
+	"complete":      {"tab"},
+	"session":       {"ctrl+t"},
+	"open":          {"ctrl+o"},
+	"choose-editor": {"alt+o"},
+	"create":        {"ctrl+n"},
+	"mark":          {" ", "ctrl+@"},
+	"mark-all":      {"ctrl+a"},
+	"actions":       {"ctrl+x"},
+	"delete":        {"ctrl+d"},
+	"rename":        {"ctrl+e"},
+	"fork":          {"ctrl+y"},
+	"worktree":      {"ctrl+w"},
+	"clone":         {"ctrl+g"},
+	"git-init":      {"ctrl+r"},
+	"sort":          {"ctrl+s"},
+	"group":         {"alt+s"},
+	"delete-char":   {"backspace"},
+	"clear-search":  {"ctrl+u"},
+	"help":          {"?"},
+	"quit":          {"ctrl+c", "q"},
+}
+
+// keyPresets change some of the default keys, moving actions out of the
+// way where their keys are taken
+var keyPresets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"up":        {"up", "ctrl+k", "ctrl+p"},
+		"down":      {"down", "ctrl+j", "ctrl+n"},
+		"page-up":   {"left", "pgup", "ctrl+b"},
+		"page-down": {"right", "pgdown", "ctrl+f"},
+		"create":    {"alt+n"},
+	},
+	"emacs": {
+		"up":        {"up", "ctrl+p"},
+		"down":      {"down", "ctrl+n"},
+		"page-up":   {"left", "pgup", "alt+v"},
+		"page-down": {"right", "pgdown", "ctrl+v"},
+		"home":      {"home", "alt+<"},
+		"end":       {"end", "alt+>"},
+		"cancel":    {"esc", "ctrl+g"},
+		"clone":     {"alt+g"},
+		"create":    {"alt+n"},
+	},
+}
+
+// Answers to yes/no questions, which aren't remappable
+var (
+	confirmYes = key.NewBinding(key.WithKeys("y", "Y"))
+	confirmNo  = key.NewBinding(key.WithKeys("n", "N"))
+)
+
+// DefaultKeyMap returns the bindings of the default preset
+func DefaultKeyMap() KeyMap {
+	keys, _ := newKeyMap("default", nil)
+	return keys
+}
+
+// LoadKeyMap returns the bindings of the keys.preset from the config, with
+// the keys.<action> overrides applied
+func LoadKeyMap() (KeyMap, error) {
+	config := core.GetConfig()
+	overrides := map[string][]string{}
+	for name := range config.Section("keys") {
+		if name != "preset" {
+			overrides[name] = config.List("keys." + name)
+		}
+	}
+	return newKeyMap(config.String("keys.preset", "default"), overrides)
+}
+
+func newKeyMap(preset string, overrides map[string][]string) (KeyMap, error) {
+	var k KeyMap
+
+	presetKeys, ok := keyPresets[preset]
+	if !ok {
+		names := slices.Sorted(maps.Keys(keyPresets))
+		return k, fmt.Errorf("invalid keys.preset %q (use %s)", preset, strings.Join(names, ", "))
+	}
+
+	actions := k.keyActions()
+	known := map[string]bool{}
+	for _, action := range actions {
+		known[action.name] = true
+	}
+	for name := range overrides {
+		if !known[name] {
+			return k, fmt.Errorf("unknown action keys.%s", name)
+		}
+	}
+
+	boundTo := map[string]string{}
+	for _, action := range actions {
+		keys := defaultKeys[action.name]
+		if presetKeys[action.name] != nil {
+			keys = presetKeys[action.name]
+		}
+		if override, ok := overrides[action.name]; ok {
+			keys = override
+		}
+
+		keys = slices.Clone(keys)
+		for i, name := range keys {
+			if name == "space" {
+				keys[i] = " "
+			}
+			if other, taken := boundTo[keys[i]]; taken {
+				return k, fmt.Errorf("%s is bound to both %s and %s, change keys.%s or keys.%s", keyLabel(keys[i]), other, action.name, other, action.name)
+			}
+			boundTo[keys[i]] = action.name
+		}
+
+		// An empty keys.<action> leaves the action without a key
+		*action.binding = key.NewBinding(key.WithKeys(keys...), key.WithHelp(keysLabel(keys), action.description))
+		if len(keys) == 0 {
+			action.binding.SetEnabled(false)
+		}
+	}
+	return k, nil
+}
+
+// keyLabel spells out a key for the help screen, e.g. "Ctrl+T"
+func keyLabel(k string) string {
+	switch k {
+	case " ":
+		return "Space"
+	case "ctrl+@":
+		return "Ctrl+Space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "pgup":
+		return "PgUp"
+	case "pgdown":
+		return "PgDn"
+	case "esc":
+		return "ESC"
+	}
+
+	parts := strings.Split(k, "+")
+	for i, part := range parts[:len(parts)-1] {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	last := parts[len(parts)-1]
+	if len(parts) > 1 {
+		last = strings.ToUpper(last)
+	} else if len(last) > 1 {
+		last = strings.ToUpper(last[:1]) + last[1:]
+	}
+	parts[len(parts)-1] = last
+	return strings.Join(parts, "+")
+}
+
+// keysLabel spells out all keys of a binding, e.g. "↑/Ctrl+K"
+func keysLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyLabel(k)
+	}
+	return strings.Join(labels, "/")
+}
+
+// shortKeyLabel abbreviates the first key of a binding for the help bar,
+// e.g. "^T"
+func shortKeyLabel(binding key.Binding) string {
+	keys := binding.Keys()
+	if len(keys) == 0 {
+		return ""
+	}
+
+	switch k := keys[0]; {
+	case k == "enter":
+		return "⏎"
+	case k == " ":
+		return "␣"
+	case strings.HasPrefix(k, "ctrl+"):
+		return "^" + strings.ToUpper(strings.TrimPrefix(k, "ctrl+"))
+	case strings.HasPrefix(k, "alt+"):
+		return "M-" + strings.TrimPrefix(k, "alt+")
+	default:
+		return keyLabel(k)
+	}
+}
@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfig points TRY_CONFIG at a new config file holding content
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_CONFIG", path)
+}
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		overrides map[string][]string
+		check     func(KeyMap) []string
+		want      []string
+	}{
+		{"defaults", "default", nil, func(k KeyMap) []string { return k.Create.Keys() }, []string{"ctrl+n"}},
+		{"vim moves create", "vim", nil, func(k KeyMap) []string { return k.Create.Keys() }, []string{"alt+n"}},
+		{"vim moves down", "vim", nil, func(k KeyMap) []string { return k.Down.Keys() }, []string{"down", "ctrl+j", "ctrl+n"}},
+		{"emacs cancels with ctrl+g", "emacs", nil, func(k KeyMap) []string { return k.Cancel.Keys() }, []string{"esc", "ctrl+g"}},
+		{"emacs keeps unchanged actions", "emacs", nil, func(k KeyMap) []string { return k.Fork.Keys() }, []string{"ctrl+y"}},
+		{"override on top of preset", "vim", map[string][]string{"create": {"ctrl+b"}, "page-up": {"pgup"}}, func(k KeyMap) []string { return k.Create.Keys() }, []string{"ctrl+b"}},
+		{"space alias", "default", map[string][]string{"mark": {"space", "ctrl+@"}}, func(k KeyMap) []string { return k.Mark.Keys() }, []string{" ", "ctrl+@"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := newKeyMap(tt.preset, tt.overrides)
+			if err != nil {
+				t.Fatalf("newKeyMap failed: %v", err)
+			}
+			if got := tt.check(keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeyMapDisablesEmptyOverride(t *testing.T) {
+	keys, err := newKeyMap("default", map[string][]string{"fork": nil})
+	if err != nil {
+		t.Fatalf("newKeyMap failed: %v", err)
+	}
+	if keys.Fork.Enabled() || len(keys.Fork.Keys()) != 0 {
+		t.Errorf("fork is still bound to %q", keys.Fork.Keys())
+	}
+	for _, item := range GetHelpContent(keys).Actions {
+		if item.Description == "Fork (copy) directory" {
+			t.Error("help lists the unbound fork action")
+		}
+	}
+
+	// The key is free for another action now
+	if _, err := newKeyMap("default", map[string][]string{"fork": nil, "rename": {"ctrl+y"}}); err != nil {
+		t.Errorf("binding the freed key failed: %v", err)
+	}
+}
+
+func TestNewKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown preset", "helix", nil, `invalid keys.preset "helix"`},
+		{"unknown action", "default", map[string][]string{"teleport": {"ctrl+z"}}, "unknown action keys.teleport"},
+		{"taken by a default", "default", map[string][]string{"fork": {"ctrl+t"}}, "Ctrl+T is bound to both session and fork"},
+		{"taken by a preset", "vim", map[string][]string{"create": {"ctrl+n"}}, "Ctrl+N is bound to both down and create"},
+		{"taken within overrides", "default", map[string][]string{"fork": {"alt+f"}, "rename": {"alt+f"}}, "Alt+F is bound to both rename and fork"},
+		{"space alias conflict", "default", map[string][]string{"select": {"space"}}, "Space is bound to both select and mark"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyMap(tt.preset, tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("newKeyMap error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// Every preset is usable without overrides
+	for preset := range keyPresets {
+		if _, err := newKeyMap(preset, nil); err != nil {
+			t.Errorf("preset %s: %v", preset, err)
+		}
+	}
+}
+
+func TestLoadKeyMap(t *testing.T) {
+	writeConfig(t, "keys.preset: emacs\nkeys.fork: ctrl+b, alt+f\nkeys.rename:\n")
+
+	keys, err := LoadKeyMap()
+	if err != nil {
+		t.Fatalf("LoadKeyMap failed: %v", err)
+	}
+	if got := keys.Fork.Keys(); !reflect.DeepEqual(got, []string{"ctrl+b", "alt+f"}) {
+		t.Errorf("fork keys = %q", got)
+	}
+	if keys.Rename.Enabled() {
+		t.Errorf("rename is bound to %q, want no key", keys.Rename.Keys())
+	}
+	if got := keys.Clone.Keys(); !reflect.DeepEqual(got, []string{"alt+g"}) {
+		t.Errorf("clone keys = %q, want the emacs preset's", got)
+	}
+
+	writeConfig(t, "keys.fork: ctrl+d\n")
+	if _, err := LoadKeyMap(); err == nil {
+		t.Error("LoadKeyMap with a conflict succeeded, want error")
+	}
+}
+
+func TestKeyLabel(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{" ", "Space"},
+		{"ctrl+@", "Ctrl+Space"},
+		{"ctrl+t", "Ctrl+T"},
+		{"alt+o", "Alt+O"},
+		{"alt+<", "Alt+<"},
+		{"up", "↑"},
+		{"pgdown", "PgDn"},
+		{"esc", "ESC"},
+		{"enter", "Enter"},
+		{"backspace", "Backspace"},
+		{"?", "?"},
+		{"q", "q"},
+	}
+
+	for _, tt := range tests {
+		if got := keyLabel(tt.key); got != tt.want {
+			t.Errorf("keyLabel(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if got := keysLabel([]string{"up", "ctrl+k"}); got != "↑/Ctrl+K" {
+		t.Errorf("keysLabel = %q, want ↑/Ctrl+K", got)
+	}
+}
+
+func TestGetHelpContentMatchesKeys(t *testing.T) {
+	for preset := range keyPresets {
+		keys, err := newKeyMap(preset, map[string][]string{"fork": {"ctrl+z", "alt+f"}})
+		if err != nil {
+			t.Fatalf("newKeyMap(%s) failed: %v", preset, err)
+		}
+		help := GetHelpContent(keys)
+		items := slices.Concat(help.Navigation, help.Actions, help.Search, help.Other)
+
+		for _, action := range keys.keyActions() {
+			want := HelpItem{keysLabel(action.binding.Keys()), action.description}
+			if !slices.Contains(items, want) {
+				t.Errorf("%s help lacks %+v", preset, want)
+			}
+		}
+		if !slices.Contains(help.Actions, HelpItem{"Ctrl+Z/Alt+F", "Fork (copy) directory"}) {
+			t.Errorf("%s help doesn't show the fork override", preset)
+		}
+	}
+}
+
+func TestGetHelpContentSpaceTip(t *testing.T) {
+	hasSpaceTip := func(keys KeyMap) string {
+		for _, tip := range GetHelpContent(keys).Tips {
+			if strings.HasPrefix(tip, "Space ") {
+				return tip
+			}
+		}
+		return ""
+	}
+
+	if tip := hasSpaceTip(DefaultKeyMap()); !strings.Contains(tip, "Ctrl+Space marks at any time") {
+		t.Errorf("default tip = %q, want it to mention Ctrl+Space", tip)
+	}
+
+	keys, _ := newKeyMap("default", map[string][]string{"mark": {"ctrl+@"}})
+	if tip := hasSpaceTip(keys); tip != "" {
+		t.Errorf("tip %q shown although Space doesn't mark", tip)
+	}
+}
+
+func TestRenderCLIKeyboardShortcuts(t *testing.T) {
+	writeConfig(t, "keys.fork: alt+f\nkeys.session:\n")
+
+	text := RenderCLIKeyboardShortcuts()
+	lines := strings.Split(text, "\n")
+	if lines[0] != "KEYBOARD SHORTCUTS (Interactive Mode):" {
+		t.Errorf("first line = %q", lines[0])
+	}
+
+	var fork string
+	for _, line := range lines {
+		if strings.HasSuffix(line, "Fork (copy) directory") {
+			fork = strings.Fields(line)[0]
+		}
+		if strings.Contains(line, "tmux/zellij") {
+			t.Errorf("unbound session action listed: %q", line)
+		}
+	}
+	if fork != "Alt+F" {
+		t.Errorf("fork listed with %q, want Alt+F", fork)
+	}
+
+	// A broken config falls back to the default keys
+	writeConfig(t, "keys.fork: ctrl+t\n")
+	if text := RenderCLIKeyboardShortcuts(); !strings.Contains(text, "Ctrl+Y") {
+		t.Errorf("shortcuts for a broken config lack the default fork key:\n%s", text)
+	}
+}
@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -11,13 +14,6 @@ import (
 	"github.com/zengjie/try/core"
 )
 
-// Column width constants for consistent layout
-const (
-	NameColumnWidth     = 50
-	TagsColumnWidth     = 15
-	ModifiedColumnWidth = 15
-)
-
 // DirectoryItem implements list.Item interface
 type DirectoryItem struct {
 	core.Directory
@@ -42,6 +38,12 @@ func (i DirectoryItem) Description() string {
 	if i.IsWorktree {
 		tags = append(tags, "🌿 worktree")
 	}
+	if i.IsAlias {
+		tags = append(tags, "📌 promoted")
+	}
+	for _, tag := range i.Tags {
+		tags = append(tags, "#"+tag)
+	}
 	
 	// Add modified time
 	age := core.GetRelativeAge(i.ModifiedTime)
@@ -52,9 +54,23 @@ func (i DirectoryItem) Description() string {
 	return age
 }
 
+// groupHeader is the title of a section of a grouped list. It is never
+// selected, the cursor skips over it.
+type groupHeader struct {
+	title string
+	count int
+}
+
+func (h groupHeader) FilterValue() string {
+	return ""
+}
+
 // Custom item delegate for rendering
 type itemDelegate struct{
 	maxWidth int
+	marked   map[string]bool
+	sortMode core.SortMode
+	columns  []Column
 }
 
 func (d itemDelegate) Height() int                             { return 1 }
@@ -62,48 +78,33 @@ func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if header, ok := listItem.(groupHeader); ok {
+		fmt.Fprint(w, groupHeaderStyle.Render(fmt.Sprintf("  %s (%d)", header.title, header.count)))
+		return
+	}
+	
 	i, ok := listItem.(DirectoryItem)
 	if !ok {
 		return
 	}
 
-	// Build the row components
-	prefix := "  "
+	// Build the row components: the cursor, then the mark
+	prefix := " "
 	if index == m.Index() {
-		prefix = "▶ "
+		prefix = "▶"
+	}
+	if d.marked[i.Path] && !i.IsCreateNew {
+		prefix += "●"
+	} else {
+		prefix += " "
 	}
 	
-	// Special rendering for "create new" items
+	layout := layoutColumns(d.maxWidth, d.columns, d.sortMode)
+	
+	// Special rendering for "create new" items, which span the columns
 	if i.IsCreateNew {
-		// Format name with special styling
-		name := i.Name
-		if len(name) > NameColumnWidth {
-			name = name[:NameColumnWidth-3] + "..."
-		}
-		// Pad name to exactly NameColumnWidth characters
-		for len(name) < NameColumnWidth {
-			name = name + " "
-		}
-		
-		// Empty tags and age for create new items
-		tags := strings.Repeat(" ", TagsColumnWidth)
-		age := strings.Repeat(" ", ModifiedColumnWidth)
-		
-		// Build the complete row
-		row := fmt.Sprintf("%s%s %s %s", prefix, name, tags, age)
-		
-		// Apply style with special color for create new
-		createItemStyle := lipgloss.NewStyle().
-			PaddingLeft(0).
-			Foreground(lipgloss.Color("#A9B665")).
-			Italic(true)
-		
-		selectedCreateItemStyle := lipgloss.NewStyle().
-			PaddingLeft(0).
-			Foreground(lipgloss.Color("#A9B665")).
-			Background(lipgloss.Color("#45475A")).
-			Italic(true).
-			Bold(true)
+		width := layout.rowWidth()
+		row := prefix + fitWidth(truncateMiddle(i.Name, width), width)
 		
 		if index == m.Index() {
 			fmt.Fprint(w, selectedCreateItemStyle.Render(row))
@@ -113,89 +114,96 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		return
 	}
 	
-	// Normal directory rendering
-	// Format name
-	name := i.Name
-	if len(name) > NameColumnWidth {
-		name = name[:NameColumnWidth-3] + "..."
-	}
-	// Pad name to exactly NameColumnWidth characters
-	for len(name) < NameColumnWidth {
-		name = name + " "
-	}
-	
-	// Format tags
-	tags := ""
-	if i.IsGitRepo {
-		tags = "git "
-	}
-	if i.IsWorktree {
-		tags = tags + "worktree "
-	}
-	if tags == "" {
-		tags = "-"
-	} else {
-		tags = strings.TrimSpace(tags)
-	}
-	// Pad tags to exactly TagsColumnWidth characters (more space for unicode)
-	for len(tags) < TagsColumnWidth {
-		tags = tags + " "
-	}
-	
-	// Format modified time
-	age := core.GetRelativeAge(i.ModifiedTime)
-	if len(age) > ModifiedColumnWidth {
-		age = age[:ModifiedColumnWidth-3] + "..."
-	}
-	// Pad age to exactly ModifiedColumnWidth characters
-	for len(age) < ModifiedColumnWidth {
-		age = age + " "
-	}
-	
-	// Build the complete row
-	row := fmt.Sprintf("%s%s %s %s", prefix, name, tags, age)
+	row := prefix + layout.row(i.Directory, d.sortMode)
 	
 	// Apply style
 	if index == m.Index() {
 		fmt.Fprint(w, selectedItemStyle.Render(row))
+	} else if d.marked[i.Path] {
+		fmt.Fprint(w, markedItemStyle.Render(row))
 	} else {
 		fmt.Fprint(w, itemStyle.Render(row))
 	}
 }
 
-// Styles for list items
-var (
-	itemStyle = lipgloss.NewStyle().
-		PaddingLeft(0).
-		Foreground(lipgloss.Color("#CDD6F4"))
-		
-	selectedItemStyle = lipgloss.NewStyle().
-		PaddingLeft(0).
-		Foreground(lipgloss.Color("#F9E2AF")).
-		Background(lipgloss.Color("#45475A"))
+// Action is what happens to the selected try once the selector exits
+type Action int
+
+const (
+	// ActionCd hands the try to the shell wrapper to cd into
+	ActionCd Action = iota
+	// ActionSession opens the try in a tmux session or zellij tab
+	ActionSession
 )
 
 type Model struct {
 	list              list.Model
+	keys              KeyMap
+	columns           []Column
 	directories       []core.Directory
 	filteredDirs      []core.Directory
 	query             string
+	sortMode          core.SortMode
+	groupMode         core.GroupMode
+	sizes             map[string]int64
+	measuring         bool
 	height            int
 	width             int
 	creating          bool
-	deleting          bool
-	deleteConfirm     bool
-	deleteInput       string
-	selectedForDelete int
+	marked            map[string]bool
+	pickingAction     bool
+	actionIndex       int
+	batchAction       batchAction
+	batchTargets      []core.Directory
+	batchSummary      []core.TrySummary
+	confirmInput      string
+	runningBatch      batchAction
+	tagging           bool
+	tagInput          string
+	fetching          bool
+	hooksRunning      bool
+	cancelWork        context.CancelFunc
+	cancellingWork    bool
+	quitAfterWork     bool
+	forking           bool
+	forkInput         string
+	forkDir           core.Directory
+	forkModes         []core.ForkOptions
+	forkMode          int
+	forkRunning       bool
+	renaming          bool
+	renameInput       string
+	renameDir         core.Directory
 	showHelp          bool
 	cloning           bool
 	cloneInput        string
+	cloneRunning      bool
+	cloneSource       string
+	cloneProgress     core.GitProgress
+	cloneUpdates      chan tea.Msg
+	cancelClone       context.CancelFunc
+	quitAfterClone    bool
 	creatingWorktree  bool
 	worktreeInput     string
 	worktreeRepo      string
+	worktreeRunning   bool
+	statusMessage     string
+	pickingTemplate   bool
+	templates         []core.Template
+	templateIndex     int
+	templateQuery     string
+	templateRunning   bool
+	pickingOpener     bool
+	openers           []string
+	openerIndex       int
 	initializingGit   bool
 	gitInitConfirm    bool
 	explicitCreating  bool
+	selectedPath      string
+	action            Action
+	maxHeight         int
+	inline            bool
+	quitting          bool
 	err               error
 }
 
@@ -203,8 +211,20 @@ func NewModel() Model {
 	// Create items list
 	items := []list.Item{}
 	
+	// Start with the order picked last time
+	sortMode, groupMode := core.SortScore, core.GroupNone
+	if state, err := core.LoadState(); err == nil {
+		if mode, err := core.ParseSortMode(state.Sort); err == nil {
+			sortMode = mode
+		}
+		if mode, err := core.ParseGroupMode(state.Group); err == nil {
+			groupMode = mode
+		}
+	}
+	
 	// Create the list with custom delegate
-	del := itemDelegate{maxWidth: 80}
+	marked := map[string]bool{}
+	del := itemDelegate{maxWidth: 80, marked: marked, sortMode: sortMode, columns: DefaultColumns}
 	l := list.New(items, del, 0, 0)
 	l.SetShowTitle(false) // Disable title completely
 	l.SetShowStatusBar(false)
@@ -217,16 +237,17 @@ func NewModel() Model {
 	
 	return Model{
 		list:              l,
+		keys:              DefaultKeyMap(),
+		columns:           DefaultColumns,
 		directories:       []core.Directory{},
 		filteredDirs:      []core.Directory{},
 		query:             "",
+		sortMode:          sortMode,
+		groupMode:         groupMode,
 		height:            24,
 		width:             80,
 		creating:          false,
-		deleting:          false,
-		deleteConfirm:     false,
-		deleteInput:       "",
-		selectedForDelete: -1,
+		marked:            marked,
 		showHelp:          false,
 		cloning:           false,
 		cloneInput:        "",
@@ -240,6 +261,28 @@ func NewModel() Model {
 	}
 }
 
+// SetKeyMap replaces the default key bindings, e.g. with LoadKeyMap's
+func (m *Model) SetKeyMap(keys KeyMap) {
+	m.keys = keys
+}
+
+// SetColumns replaces the default columns, e.g. with LoadColumns
+func (m *Model) SetColumns(columns []Column) {
+	m.columns = columns
+	m.list.SetDelegate(m.delegate())
+}
+
+// SelectedPath returns the try that was picked or created, or "" if the
+// selector was left without choosing one
+func (m *Model) SelectedPath() string {
+	return m.selectedPath
+}
+
+// SelectedAction returns what to do with SelectedPath
+func (m *Model) SelectedAction() Action {
+	return m.action
+}
+
 func (m *Model) LoadDirectories() error {
 	dirs, err := core.ScanDirectories()
 	if err != nil {
@@ -248,24 +291,57 @@ func (m *Model) LoadDirectories() error {
 	
 	m.directories = dirs
 	m.updateFiltered()
+	m.dropStaleMarks()
 	return nil
 }
 
+// LoadDirectoriesAndSelect reloads the directories, clearing the query so
+// the directory at path is visible, and moves the cursor to it
+func (m *Model) LoadDirectoriesAndSelect(path string) error {
+	m.query = ""
+	m.explicitCreating = false
+	if err := m.LoadDirectories(); err != nil {
+		return err
+	}
+	
+	m.selectPath(path)
+	return nil
+}
+
+// selectPath moves the cursor to the try at path, if it is in the list
+func (m *Model) selectPath(path string) bool {
+	for i, item := range m.list.Items() {
+		if dir, ok := item.(DirectoryItem); ok && dir.Path == path {
+			m.list.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Model) updateFiltered() {
 	// Filter and score directories using the new scoring system
-	m.filteredDirs = core.FilterAndScoreDirectories(m.directories, m.query)
-	
-	// Sort by score when there's a query, by time otherwise
-	if m.query == "" {
-		core.SortDirectoriesByTime(m.filteredDirs)
-	} else {
-		core.SortDirectoriesByScore(m.filteredDirs)
+	filtered := core.FilterAndScoreDirectories(m.directories, m.query)
+	for i := range filtered {
+		filtered[i].Size = -1
+		if size, ok := m.sizes[filtered[i].Path]; ok {
+			filtered[i].Size = size
+		}
 	}
+	core.SortDirectories(filtered, m.sortMode, m.query)
 	
-	// Convert to list items
-	items := make([]list.Item, len(m.filteredDirs))
-	for i, dir := range m.filteredDirs {
-		items[i] = DirectoryItem{Directory: dir, IsCreateNew: false}
+	// Convert to list items, with a header above every group. filteredDirs
+	// follows the order of the list.
+	m.filteredDirs = m.filteredDirs[:0]
+	var items []list.Item
+	for _, group := range core.GroupDirectories(filtered, m.groupMode, m.sortMode) {
+		if group.Title != "" {
+			items = append(items, groupHeader{title: group.Title, count: len(group.Directories)})
+		}
+		for _, dir := range group.Directories {
+			items = append(items, DirectoryItem{Directory: dir, IsCreateNew: false})
+			m.filteredDirs = append(m.filteredDirs, dir)
+		}
 	}
 	
 	// Add "Create new directory" option if query doesn't exactly match any directory
@@ -282,6 +358,168 @@ func (m *Model) updateFiltered() {
 	}
 	
 	m.list.SetItems(items)
+	
+	// Keep the cursor on the list when it shrinks
+	if m.list.Index() >= len(items) {
+		m.list.Select(max(len(items)-1, 0))
+	}
+	m.skipHeader(m.list.Index())
+}
+
+// skipHeader moves the cursor off a group header, on in the direction it
+// moved from previous, or back when there is nothing further that way
+func (m *Model) skipHeader(previous int) {
+	items := m.list.Items()
+	index := m.list.Index()
+	if index < 0 || index >= len(items) {
+		return
+	}
+	if _, ok := items[index].(groupHeader); !ok {
+		return
+	}
+	
+	step := 1
+	if index < previous {
+		step = -1
+	}
+	for _, step := range []int{step, -step} {
+		for i := index; i >= 0 && i < len(items); i += step {
+			if _, ok := items[i].(groupHeader); !ok {
+				m.list.Select(i)
+				return
+			}
+		}
+	}
+}
+
+// moveCursor runs a cursor movement and keeps the cursor off group headers
+func (m *Model) moveCursor(move func()) {
+	previous := m.list.Index()
+	move()
+	m.skipHeader(previous)
+}
+
+// Position returns the number of the selected try in the list, not counting
+// group headers
+func (m *Model) Position() int {
+	items := m.list.Items()
+	if len(items) == 0 {
+		return 0
+	}
+	
+	position := 0
+	for _, item := range items[:m.list.Index()+1] {
+		if _, ok := item.(groupHeader); !ok {
+			position++
+		}
+	}
+	return position
+}
+
+// CycleSort switches to the next sort mode and remembers it for next time.
+// Sorting by size needs the tries measured, which the returned command does.
+func (m *Model) CycleSort() tea.Cmd {
+	m.sortMode = cycle(core.SortModes, m.sortMode)
+	m.list.SetDelegate(m.delegate())
+	m.updateFiltered()
+	m.list.Select(0)
+	m.skipHeader(0)
+	m.saveOrder()
+	m.statusMessage = fmt.Sprintf("Sorted by %s", m.sortMode)
+	return m.measureSizes()
+}
+
+// CycleGroup switches to the next grouping and remembers it for next time
+func (m *Model) CycleGroup() {
+	m.groupMode = cycle(core.GroupModes, m.groupMode)
+	m.updateFiltered()
+	m.list.Select(0)
+	m.skipHeader(0)
+	m.saveOrder()
+	if m.groupMode == core.GroupNone {
+		m.statusMessage = "Not grouped"
+	} else {
+		m.statusMessage = fmt.Sprintf("Grouped by %s", m.groupMode)
+	}
+}
+
+// cycle returns the mode after current in modes
+func cycle[T comparable](modes []T, current T) T {
+	for i, mode := range modes {
+		if mode == current {
+			return modes[(i+1)%len(modes)]
+		}
+	}
+	return modes[0]
+}
+
+// OrderDescription describes the sort mode and grouping for the status bar
+func (m *Model) OrderDescription() string {
+	description := fmt.Sprintf("by %s", m.sortMode)
+	if m.groupMode != core.GroupNone {
+		description += fmt.Sprintf(", grouped by %s", m.groupMode)
+	}
+	if m.measuring {
+		description += ", measuring..."
+	}
+	return description
+}
+
+// saveOrder remembers the sort mode and grouping for the next session
+func (m *Model) saveOrder() {
+	err := core.UpdateState(func(state *core.State) {
+		state.Sort = string(m.sortMode)
+		state.Group = string(m.groupMode)
+	})
+	if err != nil {
+		m.err = err
+	}
+}
+
+// measureSizes returns a command measuring every try when sorting by size
+// or the size column needs it, or nil
+func (m *Model) measureSizes() tea.Cmd {
+	if m.sortMode != core.SortSize && !slices.Contains(m.columns, ColumnSize) || m.measuring {
+		return nil
+	}
+	
+	var missing []core.Directory
+	for _, dir := range m.directories {
+		if _, ok := m.sizes[dir.Path]; !ok {
+			missing = append(missing, dir)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	
+	m.measuring = true
+	return func() tea.Msg {
+		core.MeasureDirectories(missing)
+		sizes := make(sizesMsg, len(missing))
+		for _, dir := range missing {
+			sizes[dir.Path] = dir.Size
+		}
+		return sizes
+	}
+}
+
+// lastColumnTitle names the age column, which shows the time the list is
+// sorted by, or the modified time
+func lastColumnTitle(mode core.SortMode) string {
+	switch mode {
+	case core.SortCreated:
+		return "Created"
+	case core.SortVisited:
+		return "Visited"
+	}
+	return "Modified"
+}
+
+// delegate returns the item delegate for the current size, marks, sort and
+// columns
+func (m *Model) delegate() itemDelegate {
+	return itemDelegate{maxWidth: m.width, marked: m.marked, sortMode: m.sortMode, columns: m.columns}
 }
 
 func (m *Model) SetQuery(q string) {
@@ -314,13 +552,28 @@ func (m *Model) GetSelected() *DirectoryItem {
 	return nil
 }
 
+// SetMaxHeight limits the selector to height lines, for inline use below
+// the prompt. Zero means the whole terminal.
+func (m *Model) SetMaxHeight(height int) {
+	m.maxHeight = height
+	m.SetSize(m.width, m.height)
+}
+
+// SetInline draws the selector below the prompt instead of switching to
+// the alternate screen
+func (m *Model) SetInline() {
+	m.inline = true
+}
+
 func (m *Model) SetSize(width, height int) {
+	if m.maxHeight > 0 && height > m.maxHeight {
+		height = m.maxHeight
+	}
 	m.width = width
 	m.height = height
 	
 	// Update list with new delegate that has the correct width
-	del := itemDelegate{maxWidth: width}
-	m.list.SetDelegate(del)
+	m.list.SetDelegate(m.delegate())
 	m.list.SetWidth(width)
 	
 	// Calculate available height for list
@@ -354,48 +607,316 @@ func (m *Model) IsCreating() bool {
 	return m.explicitCreating
 }
 
-func (m *Model) StartDelete() {
-	if selectedItem := m.GetSelected(); selectedItem != nil && !selectedItem.IsCreateNew {
-		// Find the index in filteredDirs
-		for i, dir := range m.filteredDirs {
-			if dir.Path == selectedItem.Path {
-				m.deleting = true
-				m.selectedForDelete = i
-				break
-			}
+// batchAction is something done to every marked try at once
+type batchAction string
+
+const (
+	batchTrash   batchAction = "trash"
+	batchArchive batchAction = "archive"
+	batchTag     batchAction = "tag"
+	batchFetch   batchAction = "fetch"
+	batchDelete  batchAction = "delete"
+)
+
+// batchActions are offered by the Ctrl+X menu, in this order
+var batchActions = []struct {
+	action batchAction
+	label  string
+}{
+	{batchTrash, "Move to trash"},
+	{batchArchive, "Archive"},
+	{batchTag, "Tag..."},
+	{batchFetch, "git fetch"},
+	{batchDelete, "Delete permanently"},
+}
+
+// batchDoneLabels start the status message once a batch action is done,
+// e.g. "Archived 3 tries"
+var batchDoneLabels = map[batchAction]string{
+	batchTrash:   "Moved to trash:",
+	batchArchive: "Archived",
+	batchDelete:  "Deleted",
+}
+
+// batchRunningLabels are shown while a batch action is being applied
+var batchRunningLabels = map[batchAction]string{
+	batchTrash:   "🗑  Moving to trash",
+	batchArchive: "📦 Archiving",
+	batchDelete:  "⚠️  Deleting",
+}
+
+// ToggleMark marks or unmarks the selected try and moves on to the next one
+func (m *Model) ToggleMark() {
+	selected := m.GetSelected()
+	if selected == nil || selected.IsCreateNew {
+		return
+	}
+	
+	if m.marked[selected.Path] {
+		delete(m.marked, selected.Path)
+	} else {
+		m.marked[selected.Path] = true
+	}
+	m.moveCursor(m.list.CursorDown)
+}
+
+// MarkAllVisible marks every try the query matches, or unmarks them when
+// they are all marked already
+func (m *Model) MarkAllVisible() {
+	allMarked := true
+	for _, dir := range m.filteredDirs {
+		if !m.marked[dir.Path] {
+			allMarked = false
+			break
+		}
+	}
+	
+	for _, dir := range m.filteredDirs {
+		if allMarked {
+			delete(m.marked, dir.Path)
+		} else {
+			m.marked[dir.Path] = true
 		}
 	}
 }
 
-func (m *Model) CancelDelete() {
-	m.deleting = false
-	m.deleteConfirm = false
-	m.deleteInput = ""
-	m.selectedForDelete = -1
+func (m *Model) ClearMarks() {
+	clear(m.marked)
 }
 
-func (m *Model) ConfirmDelete() error {
-	if m.selectedForDelete >= 0 && m.selectedForDelete < len(m.filteredDirs) {
-		dir := m.filteredDirs[m.selectedForDelete]
-		if err := core.DeleteDirectory(dir.Path); err != nil {
-			return err
+// dropStaleMarks forgets marks of tries that are gone
+func (m *Model) dropStaleMarks() {
+	present := map[string]bool{}
+	for _, dir := range m.directories {
+		present[dir.Path] = true
+	}
+	for path := range m.marked {
+		if !present[path] {
+			delete(m.marked, path)
 		}
-		
-		// Remove from directories
-		newDirs := []core.Directory{}
-		for _, d := range m.directories {
-			if d.Path != dir.Path {
-				newDirs = append(newDirs, d)
+	}
+}
+
+// BatchTargets returns the marked tries, or the selected one when nothing
+// is marked
+func (m *Model) BatchTargets() []core.Directory {
+	var targets []core.Directory
+	if len(m.marked) > 0 {
+		for _, dir := range m.directories {
+			if m.marked[dir.Path] {
+				targets = append(targets, dir)
 			}
 		}
-		m.directories = newDirs
-		
-		m.updateFiltered()
-		m.CancelDelete()
+		return targets
+	}
+	
+	if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew {
+		targets = append(targets, selected.Directory)
+	}
+	return targets
+}
+
+// StartActionPicker asks what to do with the batch targets
+func (m *Model) StartActionPicker() {
+	if len(m.BatchTargets()) > 0 {
+		m.pickingAction = true
+		m.actionIndex = 0
+	}
+}
+
+func (m *Model) CancelActionPicker() {
+	m.pickingAction = false
+	m.actionIndex = 0
+}
+
+// StartBatchConfirm asks to confirm action on the batch targets. The
+// summary is filled in by a batchSummaryMsg once they have been measured.
+func (m *Model) StartBatchConfirm(action batchAction) {
+	m.batchTargets = m.BatchTargets()
+	if len(m.batchTargets) == 0 {
+		return
+	}
+	m.batchAction = action
+	m.batchSummary = nil
+	m.confirmInput = ""
+}
+
+func (m *Model) CancelBatchConfirm() {
+	m.batchAction = ""
+	m.batchTargets = nil
+	m.batchSummary = nil
+	m.confirmInput = ""
+}
+
+// IsConfirmed reports whether the typed confirmation allows the batch
+// action. Deleting asks for "yes", or the name when deleting a single try.
+func (m *Model) IsConfirmed() bool {
+	if m.batchSummary == nil {
+		return false
+	}
+	if m.batchAction != batchDelete {
+		return true
+	}
+	if m.confirmInput == "yes" {
+		return true
 	}
+	return len(m.batchTargets) == 1 && m.confirmInput == m.batchTargets[0].Name
+}
+
+// ConfirmBatch starts applying the confirmed action to every target in the
+// background
+func (m *Model) ConfirmBatch() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := applyBatch(ctx, m.batchAction, m.batchTargets)
+	if cmd == nil {
+		cancel()
+	} else {
+		m.runningBatch = m.batchAction
+		m.startWork(cancel)
+	}
+	m.CancelBatchConfirm()
+	return cmd
+}
+
+// startWork notes cancel as the way to stop the batch action, fetch or
+// hooks that are starting
+func (m *Model) startWork(cancel context.CancelFunc) {
+	m.cancelWork = cancel
+	m.cancellingWork = false
+	m.quitAfterWork = false
+}
+
+// finishWork forgets the finished batch action, fetch or hooks
+func (m *Model) finishWork() {
+	m.cancelWork()
+	m.cancelWork = nil
+	m.cancellingWork = false
+}
+
+// startHooks notes that hooks are about to run in the background and
+// returns the context that cancelling them ends
+func (m *Model) startHooks() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.hooksRunning = true
+	m.startWork(cancel)
+	return ctx
+}
+
+// StartFork asks for the name of a fork of the selected try and how to
+// make it
+func (m *Model) StartFork() {
+	if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew {
+		m.forking = true
+		m.forkDir = selected.Directory
+		m.forkInput = core.ExtractNameFromDirectory(selected.Name)
+		m.forkModes = forkModes(selected.Directory)
+		m.forkMode = 0
+	}
+}
+
+func (m *Model) CancelFork() {
+	m.forking = false
+	m.forkInput = ""
+	m.forkModes = nil
+	m.forkMode = 0
+}
+
+// forkModes are the ways dir can be forked. Worktrees can't be copied
+// without breaking them, only repositories can have new worktrees.
+func forkModes(dir core.Directory) []core.ForkOptions {
+	var modes []core.ForkOptions
+	if !dir.IsWorktree {
+		modes = append(modes, core.ForkOptions{}, core.ForkOptions{SkipArtifacts: true})
+	}
+	if dir.IsGitRepo || dir.IsWorktree {
+		modes = append(modes, core.ForkOptions{Worktree: true})
+	}
+	return modes
+}
+
+// StartRename asks for a new name for the selected try, starting from its
+// current name without the date
+func (m *Model) StartRename() {
+	if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew {
+		m.renaming = true
+		m.renameDir = selected.Directory
+		m.renameInput = core.ExtractNameFromDirectory(selected.Name)
+	}
+}
+
+func (m *Model) CancelRename() {
+	m.renaming = false
+	m.renameInput = ""
+	m.renameDir = core.Directory{}
+}
+
+// ConfirmRename renames the try and selects it under its new name
+func (m *Model) ConfirmRename() error {
+	dir, name := m.renameDir, m.renameInput
+	m.CancelRename()
+	
+	path, err := core.RenameDirectory(dir, name)
+	if err != nil {
+		return err
+	}
+	if m.marked[dir.Path] {
+		delete(m.marked, dir.Path)
+		m.marked[path] = true
+	}
+	
+	// Keep the query if the try still matches it
+	if err := m.LoadDirectories(); err != nil {
+		return err
+	}
+	if !m.selectPath(path) {
+		if err := m.LoadDirectoriesAndSelect(path); err != nil {
+			return err
+		}
+	}
+	m.statusMessage = fmt.Sprintf("Renamed to %s", filepath.Base(path))
 	return nil
 }
 
+// StartTagging asks for tags to add to or remove from the batch targets
+func (m *Model) StartTagging() {
+	m.batchTargets = m.BatchTargets()
+	if len(m.batchTargets) > 0 {
+		m.tagging = true
+		m.tagInput = ""
+	}
+}
+
+func (m *Model) CancelTagging() {
+	m.tagging = false
+	m.tagInput = ""
+	m.batchTargets = nil
+}
+
+// ConfirmTagging applies the typed tag edits and reloads the list so the
+// tags show up
+func (m *Model) ConfirmTagging() error {
+	edits := strings.Fields(m.tagInput)
+	targets := m.batchTargets
+	m.CancelTagging()
+	if len(edits) == 0 {
+		return nil
+	}
+	
+	if err := core.TagDirectories(targets, edits); err != nil {
+		return err
+	}
+	m.statusMessage = fmt.Sprintf("Tagged %s", countTries(len(targets)))
+	return m.LoadDirectories()
+}
+
+// countTries renders a number of tries, e.g. "1 try" or "3 tries"
+func countTries(n int) string {
+	if n == 1 {
+		return "1 try"
+	}
+	return fmt.Sprintf("%d tries", n)
+}
+
 func (m *Model) StartGitInit() {
 	if selectedItem := m.GetSelected(); selectedItem != nil && !selectedItem.IsCreateNew {
 		// Only allow Git init on regular directories (not already Git repos or worktrees)
@@ -419,4 +940,33 @@ func (m *Model) StartExplicitCreate() {
 
 func (m *Model) CancelExplicitCreate() {
 	m.explicitCreating = false
-}
\ No newline at end of file
+}
+
+// StartTemplatePicker asks which template to populate a new try with.
+// Index 0 is a plain empty directory.
+func (m *Model) StartTemplatePicker(query string, templates []core.Template) {
+	m.pickingTemplate = true
+	m.templates = templates
+	m.templateIndex = 0
+	m.templateQuery = query
+}
+
+func (m *Model) CancelTemplatePicker() {
+	m.pickingTemplate = false
+	m.templates = nil
+	m.templateIndex = 0
+	m.templateQuery = ""
+}
+
+// StartOpenerPicker asks which editor to open the selected try in
+func (m *Model) StartOpenerPicker() {
+	m.pickingOpener = true
+	m.openers = core.OpenerNames()
+	m.openerIndex = 0
+}
+
+func (m *Model) CancelOpenerPicker() {
+	m.pickingOpener = false
+	m.openers = nil
+	m.openerIndex = 0
+}
@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zengjie/try/core"
+)
+
+// Theme is the set of colors every style of the selector is built from
+type Theme struct {
+	Primary      lipgloss.TerminalColor // Title bar, borders and headers
+	Title        lipgloss.TerminalColor // Text on the title bar
+	Accent       lipgloss.TerminalColor // Prompts and highlights
+	Danger       lipgloss.TerminalColor // Errors and warnings
+	Text         lipgloss.TerminalColor // List rows
+	Dim          lipgloss.TerminalColor // Help and muted text
+	StatusBar    lipgloss.TerminalColor // Status bar background
+	StatusText   lipgloss.TerminalColor // Status bar text
+	Selection    lipgloss.TerminalColor // Selected row background
+	SelectedText lipgloss.TerminalColor // Selected row text
+	Marked       lipgloss.TerminalColor // Marked rows
+	Create       lipgloss.TerminalColor // The row that creates a new try
+}
+
+// NoColorTheme has no colors at all, for NO_COLOR. The selected row is
+// shown in reverse video instead.
+var NoColorTheme = Theme{
+	Primary:      lipgloss.NoColor{},
+	Title:        lipgloss.NoColor{},
+	Accent:       lipgloss.NoColor{},
+	Danger:       lipgloss.NoColor{},
+	Text:         lipgloss.NoColor{},
+	Dim:          lipgloss.NoColor{},
+	StatusBar:    lipgloss.NoColor{},
+	StatusText:   lipgloss.NoColor{},
+	Selection:    lipgloss.NoColor{},
+	SelectedText: lipgloss.NoColor{},
+	Marked:       lipgloss.NoColor{},
+	Create:       lipgloss.NoColor{},
+}
+
+// themes are the built-in themes by name
+var themes = map[string]Theme{
+	"dark": {
+		Primary:      lipgloss.Color("#7C3AED"), // Modern purple
+		Title:        lipgloss.Color("#FFFFFF"),
+		Accent:       lipgloss.Color("#F59E0B"), // Warm amber
+		Danger:       lipgloss.Color("#EF4444"), // Soft red
+		Text:         lipgloss.Color("#CDD6F4"),
+		Dim:          lipgloss.Color("#6C7086"),
+		StatusBar:    lipgloss.Color("#1E1E2E"),
+		StatusText:   lipgloss.Color("#E5E7EB"),
+		Selection:    lipgloss.Color("#45475A"),
+		SelectedText: lipgloss.Color("#F9E2AF"),
+		Marked:       lipgloss.Color("#89B4FA"),
+		Create:       lipgloss.Color("#A9B665"),
+	},
+	"light": {
+		Primary:      lipgloss.Color("#6D28D9"),
+		Title:        lipgloss.Color("#FFFFFF"),
+		Accent:       lipgloss.Color("#B45309"),
+		Danger:       lipgloss.Color("#B91C1C"),
+		Text:         lipgloss.Color("#1F2937"),
+		Dim:          lipgloss.Color("#6B7280"),
+		StatusBar:    lipgloss.Color("#E5E7EB"),
+		StatusText:   lipgloss.Color("#1F2937"),
+		Selection:    lipgloss.Color("#DDD6FE"),
+		SelectedText: lipgloss.Color("#1E1B4B"),
+		Marked:       lipgloss.Color("#1D4ED8"),
+		Create:       lipgloss.Color("#4D7C0F"),
+	},
+	"high-contrast": {
+		Primary:      lipgloss.Color("#00FFFF"),
+		Title:        lipgloss.Color("#000000"),
+		Accent:       lipgloss.Color("#FFFF00"),
+		Danger:       lipgloss.Color("#FF5555"),
+		Text:         lipgloss.Color("#FFFFFF"),
+		Dim:          lipgloss.Color("#C0C0C0"),
+		StatusBar:    lipgloss.Color("#000000"),
+		StatusText:   lipgloss.Color("#FFFFFF"),
+		Selection:    lipgloss.Color("#FFFFFF"),
+		SelectedText: lipgloss.Color("#000000"),
+		Marked:       lipgloss.Color("#00FF00"),
+		Create:       lipgloss.Color("#5FD7FF"),
+	},
+	"none": NoColorTheme,
+}
+
+// themeColors maps the color names used in the config to Theme fields
+func (t *Theme) themeColors() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"primary":       &t.Primary,
+		"title":         &t.Title,
+		"accent":        &t.Accent,
+		"danger":        &t.Danger,
+		"text":          &t.Text,
+		"dim":           &t.Dim,
+		"status-bar":    &t.StatusBar,
+		"status-text":   &t.StatusText,
+		"selection":     &t.Selection,
+		"selected-text": &t.SelectedText,
+		"marked":        &t.Marked,
+		"create":        &t.Create,
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseColor accepts #rgb and #rrggbb colors and ANSI color numbers
+func parseColor(value string) (lipgloss.TerminalColor, bool) {
+	if hexColor.MatchString(value) {
+		return lipgloss.Color(value), true
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(value), true
+	}
+	return nil, false
+}
+
+// LoadTheme returns the theme named by the theme config key. "auto", the
+// default, picks dark or light depending on hasDarkBackground, which may
+// ask the terminal and is only called when needed. theme.<name>.<color>
+// keys change the colors of a built-in theme or define a new one, based on
+// theme.<name>.base or else the automatic choice. NO_COLOR wins over all of
+// it.
+func LoadTheme(hasDarkBackground func() bool) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColorTheme, nil
+	}
+
+	config := core.GetConfig()
+	name := config.String("theme", "auto")
+	custom := config.Section("theme." + name)
+
+	base := name
+	if _, builtin := themes[name]; !builtin {
+		if len(custom) == 0 && name != "auto" {
+			names := slices.Sorted(maps.Keys(themes))
+			return Theme{}, fmt.Errorf("invalid theme %q (use auto, %s or define theme.%s.<color> keys)", name, strings.Join(names, ", "), name)
+		}
+		base = custom["base"]
+		if base == "" {
+			base = "auto"
+		}
+	}
+	if base == "auto" {
+		base = "light"
+		if hasDarkBackground() {
+			base = "dark"
+		}
+	}
+
+	theme, ok := themes[base]
+	if !ok {
+		return Theme{}, fmt.Errorf("invalid theme.%s.base %q", name, base)
+	}
+
+	colors := theme.themeColors()
+	for key, value := range custom {
+		if key == "base" {
+			continue
+		}
+		field, ok := colors[key]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown theme color theme.%s.%s", name, key)
+		}
+		color, ok := parseColor(value)
+		if !ok {
+			return Theme{}, fmt.Errorf("invalid theme.%s.%s %q (use #rrggbb or 0-255)", name, key, value)
+		}
+		*field = color
+	}
+	return theme, nil
+}
+
+// Styles of the selector, built by SetTheme
+var (
+	titleStyle              lipgloss.Style
+	searchBoxStyle          lipgloss.Style
+	helpViewStyle           lipgloss.Style
+	tableHeaderStyle        lipgloss.Style
+	groupHeaderStyle        lipgloss.Style
+	statusBarStyle          lipgloss.Style
+	helpStyle               lipgloss.Style
+	errorStyle              lipgloss.Style
+	deleteWarningStyle      lipgloss.Style
+	dimStyle                lipgloss.Style
+	highlightStyle          lipgloss.Style
+	itemStyle               lipgloss.Style
+	selectedItemStyle       lipgloss.Style
+	markedItemStyle         lipgloss.Style
+	createItemStyle         lipgloss.Style
+	selectedCreateItemStyle lipgloss.Style
+)
+
+// SetTheme rebuilds every style of the selector from theme
+func SetTheme(theme Theme) {
+	titleStyle = lipgloss.NewStyle().
+		Background(theme.Primary).
+		Foreground(theme.Title).
+		Bold(true).
+		Align(lipgloss.Center).
+		Padding(0, 1)
+
+	searchBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary).
+		Padding(0, 1)
+
+	helpViewStyle = lipgloss.NewStyle().
+		Padding(2, 4).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary)
+
+	tableHeaderStyle = lipgloss.NewStyle().
+		Foreground(theme.Primary).
+		Bold(true).
+		Underline(true)
+
+	groupHeaderStyle = lipgloss.NewStyle().
+		Foreground(theme.Primary).
+		Bold(true)
+
+	statusBarStyle = lipgloss.NewStyle().
+		Background(theme.StatusBar).
+		Foreground(theme.StatusText).
+		Padding(0, 1)
+
+	helpStyle = lipgloss.NewStyle().
+		Foreground(theme.Dim)
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(theme.Danger).
+		Bold(true)
+
+	deleteWarningStyle = lipgloss.NewStyle().
+		Foreground(theme.Danger).
+		Bold(true)
+
+	dimStyle = lipgloss.NewStyle().
+		Foreground(theme.Dim)
+
+	highlightStyle = lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+
+	itemStyle = lipgloss.NewStyle().
+		Foreground(theme.Text)
+
+	selectedItemStyle = lipgloss.NewStyle().
+		Foreground(theme.SelectedText).
+		Background(theme.Selection)
+
+	markedItemStyle = lipgloss.NewStyle().
+		Foreground(theme.Marked)
+
+	createItemStyle = lipgloss.NewStyle().
+		Foreground(theme.Create).
+		Italic(true)
+
+	selectedCreateItemStyle = lipgloss.NewStyle().
+		Foreground(theme.Create).
+		Background(theme.Selection).
+		Italic(true).
+		Bold(true)
+
+	// Without a selection color the selected row stands out by reversing
+	if _, ok := theme.Selection.(lipgloss.NoColor); ok {
+		selectedItemStyle = selectedItemStyle.Reverse(true)
+		selectedCreateItemStyle = selectedCreateItemStyle.Reverse(true)
+	}
+}
+
+func init() {
+	SetTheme(themes["dark"])
+}
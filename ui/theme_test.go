@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  lipgloss.TerminalColor
+	}{
+		{"#7C3AED", lipgloss.Color("#7C3AED")},
+		{"#fff", lipgloss.Color("#fff")},
+		{"0", lipgloss.Color("0")},
+		{"255", lipgloss.Color("255")},
+		{"256", nil},
+		{"-1", nil},
+		{"#12345", nil},
+		{"#GGGGGG", nil},
+		{"7C3AED", nil},
+		{"purple", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseColor(tt.value)
+		if ok != (tt.want != nil) || got != tt.want {
+			t.Errorf("parseColor(%q) = %v, %v, want %v", tt.value, got, ok, tt.want)
+		}
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	withColors := func(theme Theme, colors map[string]string) Theme {
+		fields := theme.themeColors()
+		for name, value := range colors {
+			*fields[name] = lipgloss.Color(value)
+		}
+		return theme
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		dark   bool
+		want   Theme
+		asked  bool // Whether the terminal background is needed
+	}{
+		{"auto on a dark terminal", "", true, themes["dark"], true},
+		{"auto on a light terminal", "", false, themes["light"], true},
+		{"explicit auto", "theme: auto\n", false, themes["light"], true},
+		{"built-in", "theme: high-contrast\n", true, themes["high-contrast"], false},
+		{"none", "theme: none\n", true, NoColorTheme, false},
+		{
+			"changed built-in",
+			"theme: light\ntheme.light.accent: #FF0000\ntheme.light.marked: 33\n",
+			true,
+			withColors(themes["light"], map[string]string{"accent": "#FF0000", "marked": "33"}),
+			false,
+		},
+		{
+			"custom with base",
+			"theme: mine\ntheme.mine.base: dark\ntheme.mine.Primary: #123\n",
+			false,
+			withColors(themes["dark"], map[string]string{"primary": "#123"}),
+			false,
+		},
+		{
+			"custom without base",
+			"theme: mine\ntheme.mine.selection: 240\n",
+			false,
+			withColors(themes["light"], map[string]string{"selection": "240"}),
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, tt.config)
+			t.Setenv("NO_COLOR", "")
+
+			asked := false
+			got, err := LoadTheme(func() bool {
+				asked = true
+				return tt.dark
+			})
+			if err != nil {
+				t.Fatalf("LoadTheme failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadTheme() = %+v, want %+v", got, tt.want)
+			}
+			if asked != tt.asked {
+				t.Errorf("terminal background asked: %v, want %v", asked, tt.asked)
+			}
+		})
+	}
+}
+
+func TestLoadThemeNoColor(t *testing.T) {
+	writeConfig(t, "theme: mine\ntheme.mine.base: dark\ntheme.mine.accent: #FF0000\n")
+	t.Setenv("NO_COLOR", "1")
+
+	got, err := LoadTheme(func() bool {
+		t.Error("NO_COLOR asked for the terminal background")
+		return true
+	})
+	if err != nil || !reflect.DeepEqual(got, NoColorTheme) {
+		t.Errorf("LoadTheme() with NO_COLOR = %+v, %v, want no colors", got, err)
+	}
+
+	// Even a broken theme doesn't matter then
+	writeConfig(t, "theme: missing\n")
+	if _, err := LoadTheme(func() bool { return true }); err != nil {
+		t.Errorf("LoadTheme() with NO_COLOR failed: %v", err)
+	}
+}
+
+func TestLoadThemeErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{"theme: solarized\n", `invalid theme "solarized" (use auto, dark, high-contrast, light, none`},
+		{"theme: mine\ntheme.mine.base: solarized\n", `invalid theme.mine.base "solarized"`},
+		{"theme: dark\ntheme.dark.sparkle: #FFF\n", "unknown theme color theme.dark.sparkle"},
+		{"theme: dark\ntheme.dark.accent: orange\n", `invalid theme.dark.accent "orange"`},
+		{"theme: mine\ntheme.mine.text: #1234567\n", `invalid theme.mine.text "#1234567"`},
+	}
+
+	for _, tt := range tests {
+		writeConfig(t, tt.config)
+		t.Setenv("NO_COLOR", "")
+
+		_, err := LoadTheme(func() bool { return true })
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadTheme() with %q: error %v, want %q", tt.config, err, tt.want)
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package ui
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/zengjie/try/core"
 )
@@ -19,10 +25,83 @@ type DirectoryCreatedMsg struct {
 	Path string
 }
 
+// cloneProgressMsg carries a progress update from a running clone
+type cloneProgressMsg core.GitProgress
+
+// cloneDoneMsg is sent when a background clone finishes or is cancelled
+type cloneDoneMsg struct {
+	path string
+	err  error
+}
+
+// editorDoneMsg is sent when an editor running in the terminal exits
+type editorDoneMsg struct {
+	name string
+	err  error
+}
+
+// batchSummaryMsg carries the measurements of the tries a batch action is
+// about to change
+type batchSummaryMsg []core.TrySummary
+
+// sizesMsg carries the sizes of tries measured for sorting by size, keyed
+// by path
+type sizesMsg map[string]int64
+
+// batchDoneMsg is sent when a batch action has been applied to every
+// target
+type batchDoneMsg struct {
+	action    batchAction
+	applied   int
+	cancelled bool
+	err       error
+}
+
+// fetchDoneMsg is sent when git fetch has run in every marked repository,
+// or was cancelled
+type fetchDoneMsg struct {
+	results   []core.ExecResult
+	cancelled bool
+}
+
+// hooksDoneMsg is sent when the hooks for entering or creating a try have
+// run. The try is then opened with opener, or handed to the shell for action
+// when there is none.
+type hooksDoneMsg struct {
+	path    string
+	name    string
+	visited string // Path to record a visit for, "" for a new try
+	action  Action
+	opener  *core.Opener
+	err     error
+}
+
+// forkDoneMsg is sent when a background fork finishes
+type forkDoneMsg struct {
+	path string
+	err  error
+}
+
+// templateDoneMsg is sent when a try created from a template is ready
+type templateDoneMsg struct {
+	path string
+	err  error
+}
+
+// worktreeDoneMsg is sent when a background worktree creation finishes
+type worktreeDoneMsg struct {
+	path string
+	err  error
+}
+
 func (m Model) Init() tea.Cmd {
+	if m.inline {
+		return tea.WindowSize()
+	}
 	return tea.Batch(
 		tea.EnterAltScreen,
 		tea.WindowSize(),
+		m.measureSizes(),
 	)
 }
 
@@ -38,25 +117,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Background work only listens for cancellation
+		if m.cloneRunning {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.cancelClone()
+				m.cloneProgress = core.GitProgress{Phase: "Cancelling", Percent: -1}
+			case msg.String() == "ctrl+c":
+				// Quit once git has been stopped and the partial clone removed
+				m.cancelClone()
+				m.quitAfterClone = true
+			}
+			return m, nil
+		}
+
+		if m.fetching || m.runningBatch != "" || m.hooksRunning {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.cancelWork()
+				m.cancellingWork = true
+			case msg.String() == "ctrl+c":
+				// Quit once the commands have been stopped, so no try is
+				// left half deleted or moved
+				m.cancelWork()
+				m.cancellingWork = true
+				m.quitAfterWork = true
+			}
+			return m, nil
+		}
+
+		if m.worktreeRunning || m.forkRunning || m.templateRunning {
+			return m, nil
+		}
+
 		// Handle special input modes
 		if m.creatingWorktree {
-			switch msg.String() {
-			case "enter":
-				if err := createWorktreeFromPath(m.worktreeRepo, m.worktreeInput); err != nil {
-					m.err = err
-				} else {
-					m.creatingWorktree = false
-					m.worktreeInput = ""
-					m.worktreeRepo = ""
-					m.LoadDirectories()
-				}
-				return m, nil
-			case "esc":
+			switch {
+			case key.Matches(msg, m.keys.Select):
+				m.creatingWorktree = false
+				m.worktreeRunning = true
+				return m, createWorktreeFromPath(m.worktreeRepo, m.worktreeInput)
+			case key.Matches(msg, m.keys.Cancel):
 				m.creatingWorktree = false
 				m.worktreeInput = ""
 				m.worktreeRepo = ""
 				return m, nil
-			case "backspace":
+			case key.Matches(msg, m.keys.DeleteChar):
 				if len(m.worktreeInput) > 0 {
 					m.worktreeInput = m.worktreeInput[:len(m.worktreeInput)-1]
 				}
@@ -73,23 +179,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.cloning {
-			switch msg.String() {
-			case "enter":
+			switch {
+			case key.Matches(msg, m.keys.Select):
 				if m.cloneInput != "" {
-					if err := cloneRepository(m.cloneInput); err != nil {
-						m.err = err
-					} else {
-						m.cloning = false
-						m.cloneInput = ""
-						m.LoadDirectories()
-					}
+					ctx, cancel := context.WithCancel(context.Background())
+					m.cloning = false
+					m.cloneRunning = true
+					m.cloneSource = m.cloneInput
+					m.cloneProgress = core.GitProgress{Percent: -1}
+					m.cloneUpdates = make(chan tea.Msg, 1)
+					m.cancelClone = cancel
+					m.cloneInput = ""
+					return m, tea.Batch(
+						cloneRepository(ctx, m.cloneSource, m.cloneUpdates),
+						waitForCloneProgress(m.cloneUpdates),
+					)
 				}
 				return m, nil
-			case "esc":
+			case key.Matches(msg, m.keys.Cancel):
 				m.cloning = false
 				m.cloneInput = ""
 				return m, nil
-			case "backspace":
+			case key.Matches(msg, m.keys.DeleteChar):
 				if len(m.cloneInput) > 0 {
 					m.cloneInput = m.cloneInput[:len(m.cloneInput)-1]
 				}
@@ -105,39 +216,211 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		if m.deleting && m.deleteConfirm {
-			switch msg.String() {
-			case "enter":
-				dir := m.filteredDirs[m.selectedForDelete]
-				if m.deleteInput == "yes" || m.deleteInput == dir.Name {
-					if err := m.ConfirmDelete(); err != nil {
-						m.err = err
+		if m.batchAction == batchDelete {
+			switch {
+			case key.Matches(msg, m.keys.Select):
+				// Wait for the summary, it is what is being confirmed
+				if m.batchSummary == nil {
+					return m, nil
+				}
+				if m.IsConfirmed() {
+					return m, m.ConfirmBatch()
+				}
+				m.CancelBatchConfirm()
+				return m, nil
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelBatchConfirm()
+				return m, nil
+			case key.Matches(msg, m.keys.DeleteChar):
+				if len(m.confirmInput) > 0 {
+					m.confirmInput = m.confirmInput[:len(m.confirmInput)-1]
+				}
+				return m, nil
+			default:
+				if len(msg.String()) == 1 {
+					r := []rune(msg.String())[0]
+					if r >= 32 && r < 127 {
+						m.confirmInput += string(r)
 					}
 				}
-				m.CancelDelete()
 				return m, nil
-			case "esc":
-				m.CancelDelete()
+			}
+		}
+
+		if m.batchAction != "" {
+			switch {
+			case key.Matches(msg, confirmYes, m.keys.Select):
+				if m.IsConfirmed() {
+					return m, m.ConfirmBatch()
+				}
+				return m, nil
+			case key.Matches(msg, confirmNo, m.keys.Cancel):
+				m.CancelBatchConfirm()
+				return m, nil
+			}
+			return m, nil
+		}
+
+		if m.tagging {
+			switch {
+			case key.Matches(msg, m.keys.Select):
+				if err := m.ConfirmTagging(); err != nil {
+					m.err = err
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelTagging()
+				return m, nil
+			case key.Matches(msg, m.keys.DeleteChar):
+				if len(m.tagInput) > 0 {
+					m.tagInput = m.tagInput[:len(m.tagInput)-1]
+				}
+				return m, nil
+			default:
+				if len(msg.String()) == 1 {
+					r := []rune(msg.String())[0]
+					if r >= 32 && r < 127 {
+						m.tagInput += string(r)
+					}
+				}
+				return m, nil
+			}
+		}
+
+		if m.forking {
+			switch {
+			case key.Matches(msg, m.keys.Select):
+				opts := m.forkModes[m.forkMode]
+				opts.Name = m.forkInput
+				dir := m.forkDir
+				m.CancelFork()
+				m.forkRunning = true
+				return m, forkTry(dir, opts)
+			case msg.String() == "tab":
+				m.forkMode = (m.forkMode + 1) % len(m.forkModes)
+				return m, nil
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelFork()
 				return m, nil
-			case "backspace":
-				if len(m.deleteInput) > 0 {
-					m.deleteInput = m.deleteInput[:len(m.deleteInput)-1]
+			case key.Matches(msg, m.keys.DeleteChar):
+				if len(m.forkInput) > 0 {
+					m.forkInput = m.forkInput[:len(m.forkInput)-1]
 				}
 				return m, nil
 			default:
 				if len(msg.String()) == 1 {
 					r := []rune(msg.String())[0]
 					if r >= 32 && r < 127 {
-						m.deleteInput += string(r)
+						m.forkInput += string(r)
 					}
 				}
 				return m, nil
 			}
 		}
 
+		if m.renaming {
+			switch {
+			case key.Matches(msg, m.keys.Select):
+				// The prompt explains names that can't be used
+				if _, err := core.RenameTarget(m.renameDir, m.renameInput); err != nil {
+					return m, nil
+				}
+				if err := m.ConfirmRename(); err != nil {
+					m.err = err
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelRename()
+				return m, nil
+			case key.Matches(msg, m.keys.DeleteChar):
+				if len(m.renameInput) > 0 {
+					m.renameInput = m.renameInput[:len(m.renameInput)-1]
+				}
+				return m, nil
+			default:
+				if len(msg.String()) == 1 {
+					r := []rune(msg.String())[0]
+					if r >= 32 && r < 127 {
+						m.renameInput += string(r)
+					}
+				}
+				return m, nil
+			}
+		}
+
+		if m.pickingAction {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.actionIndex > 0 {
+					m.actionIndex--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.actionIndex < len(batchActions)-1 {
+					m.actionIndex++
+				}
+			case key.Matches(msg, m.keys.Select):
+				action := batchActions[m.actionIndex].action
+				m.CancelActionPicker()
+				return m.startBatchAction(action)
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelActionPicker()
+			}
+			return m, nil
+		}
+
+		if m.pickingTemplate {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.templateIndex > 0 {
+					m.templateIndex--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.templateIndex < len(m.templates) {
+					m.templateIndex++
+				}
+			case key.Matches(msg, m.keys.Select):
+				query, index := m.templateQuery, m.templateIndex
+				templates := m.templates
+				m.CancelTemplatePicker()
+				if index > 0 {
+					// Templates may run a slow post-create hook
+					m.templateRunning = true
+					return m, createFromTemplate(query, templates[index-1])
+				}
+				return m, createTry(m.startHooks(), query, ActionCd)
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelTemplatePicker()
+			}
+			return m, nil
+		}
+
+		if m.pickingOpener {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.openerIndex > 0 {
+					m.openerIndex--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.openerIndex < len(m.openers)-1 {
+					m.openerIndex++
+				}
+			case key.Matches(msg, m.keys.Select):
+				opener, err := core.FindOpener(m.openers[m.openerIndex])
+				m.CancelOpenerPicker()
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+				return m.openSelected(opener)
+			case key.Matches(msg, m.keys.Cancel):
+				m.CancelOpenerPicker()
+			}
+			return m, nil
+		}
+
 		if m.initializingGit && m.gitInitConfirm {
-			switch msg.String() {
-			case "y", "Y", "enter":
+			switch {
+			case key.Matches(msg, confirmYes, m.keys.Select):
 				if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew {
 					if err := initializeGitRepository(selected.Path); err != nil {
 						m.err = err
@@ -147,7 +430,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.CancelGitInit()
 				return m, nil
-			case "n", "N", "esc":
+			case key.Matches(msg, confirmNo, m.keys.Cancel):
 				m.CancelGitInit()
 				return m, nil
 			}
@@ -155,54 +438,102 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Normal mode key handling
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
+		m.statusMessage = ""
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
 
-		case "?":
+		case key.Matches(msg, m.keys.Help):
 			m.showHelp = true
 			return m, nil
 
-		case "/":
-			// Start search mode by appending to query
-			m.AppendToQuery('/')
-			return m, nil
-
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			// Check what's selected
 			if selected := m.GetSelected(); selected != nil {
 				if selected.IsCreateNew {
-					// Create new directory
-					path, err := core.CreateDirectory(selected.CreateQuery)
-					if err != nil {
-						m.err = err
+					// Offer templates before creating, if there are any
+					if templates, _ := core.ListTemplates(); len(templates) > 0 {
+						m.StartTemplatePicker(selected.CreateQuery, templates)
 						return m, nil
 					}
-					writeCdPath(path)
-					return m, tea.Quit
+					
+					// Create new directory
+					return m, createTry(m.startHooks(), selected.CreateQuery, ActionCd)
 				} else {
-					// Select existing directory
-					writeCdPath(selected.Path)
-					return m, tea.Quit
+					// Select existing directory, unless an on-enter hook objects
+					return m, enterTry(m.startHooks(), selected.Directory, ActionCd, nil)
+				}
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Session):
+			// Open in a multiplexer session instead of changing directory
+			if selected := m.GetSelected(); selected != nil {
+				if selected.IsCreateNew {
+					return m, createTry(m.startHooks(), selected.CreateQuery, ActionSession)
 				}
+				return m, enterTry(m.startHooks(), selected.Directory, ActionSession, nil)
 			}
 			return m, nil
 
-		case "tab":
+		case key.Matches(msg, m.keys.Open):
+			opener, err := core.DefaultOpener()
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			return m.openSelected(opener)
+
+		case key.Matches(msg, m.keys.ChooseEditor):
+			if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew {
+				m.StartOpenerPicker()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Complete):
 			// Auto-complete
 			if len(m.filteredDirs) > 0 && m.query != "" {
 				m.SetQuery(m.filteredDirs[0].Name)
 			}
 			return m, nil
 
-		case "ctrl+d":
-			m.StartDelete()
-			if m.deleting {
-				m.deleteConfirm = true
+		case key.Matches(msg, m.keys.Delete):
+			return m.startBatchAction(batchDelete)
+
+		case key.Matches(msg, m.keys.Fork):
+			m.StartFork()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Rename):
+			m.StartRename()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Actions):
+			m.StartActionPicker()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Sort):
+			return m, m.CycleSort()
+
+		case key.Matches(msg, m.keys.Group):
+			m.CycleGroup()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Mark):
+			// Space marks until something is typed, after that it separates
+			// search words
+			if msg.String() == " " && m.query != "" {
+				m.AppendToQuery(' ')
+			} else {
+				m.ToggleMark()
 			}
 			return m, nil
 
-		case "ctrl+w":
+		case key.Matches(msg, m.keys.MarkAll):
+			m.MarkAllVisible()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Worktree):
 			if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew {
 				if isGitRepository(selected.Path) {
 					m.creatingWorktree = true
@@ -212,55 +543,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "ctrl+g":
+		case key.Matches(msg, m.keys.Clone):
 			m.cloning = true
 			return m, nil
 
-		case "ctrl+r":
+		case key.Matches(msg, m.keys.GitInit):
 			m.StartGitInit()
 			return m, nil
 
-		case "ctrl+u":
+		case key.Matches(msg, m.keys.ClearSearch):
 			m.SetQuery("")
 			return m, nil
 
-		case "ctrl+j":
-			// Move down (hidden navigation feature)
-			m.list.CursorDown()
+		case key.Matches(msg, m.keys.Down):
+			m.moveCursor(m.list.CursorDown)
 			return m, nil
 
-		case "ctrl+k":
-			// Move up (hidden navigation feature)
-			m.list.CursorUp()
+		case key.Matches(msg, m.keys.Up):
+			m.moveCursor(m.list.CursorUp)
 			return m, nil
 
-		case "ctrl+p":
-			// Page up (hidden navigation feature)
-			m.list.Paginator.PrevPage()
+		case key.Matches(msg, m.keys.PageUp):
+			m.moveCursor(func() { m.list.Select(max(m.list.Index()-m.list.Paginator.PerPage, 0)) })
 			return m, nil
 
-		case "ctrl+n":
-			m.StartExplicitCreate()
+		case key.Matches(msg, m.keys.PageDown):
+			m.moveCursor(func() { m.list.Select(min(m.list.Index()+m.list.Paginator.PerPage, len(m.list.Items())-1)) })
 			return m, nil
 
-		case "ctrl+f":
-			// Page down (hidden navigation feature)
-			m.list.Paginator.NextPage()
+		case key.Matches(msg, m.keys.Home):
+			m.moveCursor(func() { m.list.Select(0) })
 			return m, nil
 
-		case "backspace":
+		case key.Matches(msg, m.keys.End):
+			m.moveCursor(func() { m.list.Select(len(m.list.Items()) - 1) })
+			return m, nil
+
+		case key.Matches(msg, m.keys.Create):
+			m.StartExplicitCreate()
+			return m, nil
+
+		case key.Matches(msg, m.keys.DeleteChar):
 			m.DeleteFromQuery()
 			return m, nil
 
-		case "esc":
+		case key.Matches(msg, m.keys.Cancel):
 			if m.explicitCreating {
 				m.CancelExplicitCreate()
 			} else if m.query != "" {
 				m.SetQuery("")
-			} else if m.deleting {
-				m.CancelDelete()
+			} else if len(m.marked) > 0 {
+				m.ClearMarks()
 			} else {
-				return m, tea.Quit
+				return m.quit()
 			}
 			return m, nil
 
@@ -271,10 +606,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if r >= 32 && r < 127 {
 					m.AppendToQuery(r)
 				}
-			} else {
-				// Let list handle other navigation keys
-				m.list, cmd = m.list.Update(msg)
-				cmds = append(cmds, cmd)
 			}
 		}
 
@@ -283,9 +614,167 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list, cmd = m.list.Update(msg)
 		return m, cmd
 
+	case cloneProgressMsg:
+		if !m.cloneRunning {
+			return m, nil
+		}
+		if m.cloneProgress.Phase != "Cancelling" {
+			m.cloneProgress = core.GitProgress(msg)
+		}
+		return m, waitForCloneProgress(m.cloneUpdates)
+
+	case cloneDoneMsg:
+		m.cloneRunning = false
+		m.cancelClone = nil
+		if m.quitAfterClone {
+			return m.quit()
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.statusMessage = "Clone cancelled"
+			return m, nil
+		}
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		if err := m.LoadDirectoriesAndSelect(msg.path); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Cloned into %s, press Enter to go there", filepath.Base(msg.path))
+		return m, nil
+
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("%s failed: %w", msg.name, msg.err)
+		}
+		return m, nil
+
+	case sizesMsg:
+		m.measuring = false
+		if m.sizes == nil {
+			m.sizes = map[string]int64{}
+		}
+		maps.Copy(m.sizes, msg)
+		// The cursor stays on the try it was moved to, or at the top
+		if selected := m.GetSelected(); selected != nil && !selected.IsCreateNew && m.Position() > 1 {
+			m.updateFiltered()
+			m.selectPath(selected.Path)
+		} else {
+			m.updateFiltered()
+		}
+		return m, nil
+
+	case batchSummaryMsg:
+		// Ignore measurements for a confirmation that was cancelled
+		if m.batchAction != "" && m.batchSummary == nil && len(msg) == len(m.batchTargets) {
+			m.batchSummary = msg
+		}
+		return m, nil
+
+	case batchDoneMsg:
+		m.runningBatch = ""
+		m.finishWork()
+		if m.quitAfterWork {
+			return m.quit()
+		}
+		m.statusMessage = fmt.Sprintf("%s %s", batchDoneLabels[msg.action], countTries(msg.applied))
+		if msg.cancelled {
+			m.statusMessage += ", then cancelled"
+		}
+		m.ClearMarks()
+		errs := []error{msg.err}
+		if err := m.LoadDirectories(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := errors.Join(errs...); err != nil {
+			m.err = err
+		}
+		return m, nil
+
+	case fetchDoneMsg:
+		m.fetching = false
+		m.finishWork()
+		if m.quitAfterWork {
+			return m.quit()
+		}
+		if msg.cancelled {
+			m.statusMessage = "Fetch cancelled"
+		} else if len(msg.results) == 0 {
+			m.statusMessage = "No git repositories to fetch"
+		} else if failed := core.FailedNames(msg.results); failed != "" {
+			m.statusMessage = fmt.Sprintf("git fetch failed in %s", failed)
+		} else {
+			m.statusMessage = fmt.Sprintf("Fetched %s", countRepositories(len(msg.results)))
+		}
+		return m, nil
+
+	case hooksDoneMsg:
+		m.hooksRunning = false
+		m.finishWork()
+		if m.quitAfterWork {
+			return m.quit()
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.statusMessage = "Hooks cancelled"
+			return m, nil
+		}
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		if msg.visited != "" {
+			// For sorting by last visit. It isn't worth an error.
+			core.RecordVisit(msg.visited)
+		}
+		if msg.opener != nil {
+			return m.launchOpener(*msg.opener, msg.path, msg.name)
+		}
+		m.selectedPath = msg.path
+		m.action = msg.action
+		return m.quit()
+
+	case forkDoneMsg:
+		m.forkRunning = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		if err := m.LoadDirectoriesAndSelect(msg.path); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Forked into %s, press Enter to go there", filepath.Base(msg.path))
+		return m, nil
+
+	case templateDoneMsg:
+		m.templateRunning = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.selectedPath = msg.path
+		return m.quit()
+
+	case worktreeDoneMsg:
+		m.worktreeRunning = false
+		m.worktreeInput = ""
+		m.worktreeRepo = ""
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		if err := m.LoadDirectoriesAndSelect(msg.path); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Created worktree %s, press Enter to go there", filepath.Base(msg.path))
+		return m, nil
+
 	case directoriesLoadedMsg:
 		m.directories = msg.dirs
 		m.updateFiltered()
+		m.dropStaleMarks()
 		return m, nil
 
 	case error:
@@ -305,6 +794,134 @@ type directoriesLoadedMsg struct {
 	dirs []core.Directory
 }
 
+// quit ends the program. The final frame is blank so an inline selector
+// leaves nothing behind on the terminal.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// openSelected opens the selected try with opener once its on-enter hooks
+// have run, and keeps the selector running
+func (m Model) openSelected(opener core.Opener) (tea.Model, tea.Cmd) {
+	selected := m.GetSelected()
+	if selected == nil || selected.IsCreateNew {
+		return m, nil
+	}
+	return m, enterTry(m.startHooks(), selected.Directory, ActionCd, &opener)
+}
+
+// launchOpener opens the try at path with opener. GUI apps are started in
+// the background; terminal editors take over the screen until they exit.
+func (m Model) launchOpener(opener core.Opener, path, name string) (tea.Model, tea.Cmd) {
+	cmd, err := opener.Command([]string{path})
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	if opener.Detach {
+		if err := core.StartDetached(cmd); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Opened %s in %s", name, opener.Name)
+		return m, nil
+	}
+
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorDoneMsg{name: opener.Name, err: err}
+	})
+}
+
+// startBatchAction starts action on the marked tries, or the selected one.
+// Tagging asks for the tags, fetching starts right away and the rest wait
+// for confirmation while the tries are measured in the background.
+func (m Model) startBatchAction(action batchAction) (tea.Model, tea.Cmd) {
+	switch action {
+	case batchTag:
+		m.StartTagging()
+		return m, nil
+	case batchFetch:
+		targets := m.BatchTargets()
+		if len(targets) == 0 {
+			return m, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.fetching = true
+		m.startWork(cancel)
+		return m, fetchTries(ctx, targets)
+	}
+	
+	m.StartBatchConfirm(action)
+	if m.batchAction == "" {
+		return m, nil
+	}
+	return m, summarizeTries(m.batchTargets)
+}
+
+// summarizeTries measures the tries a batch action is about to change
+func summarizeTries(targets []core.Directory) tea.Cmd {
+	return func() tea.Msg {
+		return batchSummaryMsg(core.SummarizeDirectories(targets))
+	}
+}
+
+// applyBatch applies action to every target in the background, carrying
+// on past failures. Cancelling ctx stops it before the next target.
+func applyBatch(ctx context.Context, action batchAction, targets []core.Directory) tea.Cmd {
+	var apply func(string) error
+	switch action {
+	case batchDelete:
+		apply = core.DeleteDirectory
+	case batchTrash:
+		apply = func(path string) error {
+			_, err := core.TrashDirectory(path)
+			return err
+		}
+	case batchArchive:
+		apply = func(path string) error {
+			_, err := core.ArchiveDirectory(path)
+			return err
+		}
+	default:
+		return nil
+	}
+
+	return func() tea.Msg {
+		var errs []error
+		applied := 0
+		for _, dir := range targets {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := apply(dir.Path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to %s %s: %w", action, dir.Name, err))
+				continue
+			}
+			applied++
+		}
+		return batchDoneMsg{action: action, applied: applied, cancelled: ctx.Err() != nil, err: errors.Join(errs...)}
+	}
+}
+
+// fetchTries runs git fetch in every repository among targets until ctx
+// is cancelled
+func fetchTries(ctx context.Context, targets []core.Directory) tea.Cmd {
+	return func() tea.Msg {
+		results := core.FetchDirectories(ctx, targets, core.GetConfig().Int("exec.jobs", 4))
+		return fetchDoneMsg{results: results, cancelled: ctx.Err() != nil}
+	}
+}
+
+// countRepositories renders a number of repositories, e.g. "1 repository"
+func countRepositories(n int) string {
+	if n == 1 {
+		return "1 repository"
+	}
+	return fmt.Sprintf("%d repositories", n)
+}
+
 func loadDirectories() tea.Cmd {
 	return func() tea.Msg {
 		dirs, err := core.ScanDirectories()
@@ -315,12 +932,6 @@ func loadDirectories() tea.Cmd {
 	}
 }
 
-func writeCdPath(path string) {
-	home, _ := os.UserHomeDir()
-	cdFile := filepath.Join(home, ".try_cd")
-	os.WriteFile(cdFile, []byte(path), 0644)
-}
-
 func isGitRepository(path string) bool {
 	gitPath := filepath.Join(path, ".git")
 	info, err := os.Stat(gitPath)
@@ -330,54 +941,109 @@ func isGitRepository(path string) bool {
 	return info.IsDir()
 }
 
-func cloneRepository(url string) error {
-	core.EnsureTryDirectory()
-	
-	// Extract name from URL
-	name := core.ExtractNameFromGitURL(url)
-	dirName := core.GenerateDatedName(name)
-	fullPath := filepath.Join(core.GetTryPath(), dirName)
-	
-	// Clone the repository
-	cmd := exec.Command("git", "clone", url, fullPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+// enterTry runs the on-enter hooks of dir in the background
+func enterTry(ctx context.Context, dir core.Directory, action Action, opener *core.Opener) tea.Cmd {
+	return func() tea.Msg {
+		path := dir.TargetPath()
+		err := core.RunHooksContext(ctx, core.HookOnEnter, path, core.HookOutput)
+		return hooksDoneMsg{path: path, name: dir.Name, visited: dir.Path, action: action, opener: opener, err: err}
 	}
-	
-	// Write the path for cd
-	writeCdPath(fullPath)
-	
-	return nil
 }
 
-func createWorktreeFromPath(repoPath, name string) error {
-	if name == "" {
-		name = filepath.Base(repoPath) + "-worktree"
+// createTry creates an empty try and runs its on-create hooks in the
+// background
+func createTry(ctx context.Context, query string, action Action) tea.Cmd {
+	return func() tea.Msg {
+		path, err := core.CreateDirectoryContext(ctx, query)
+		return hooksDoneMsg{path: path, action: action, err: err}
 	}
-	
-	// Generate dated name for the worktree
-	worktreeName := core.GenerateDatedName(name)
-	worktreePath := filepath.Join(core.GetTryPath(), worktreeName)
-	
-	// Create a unique branch name
-	branchName := fmt.Sprintf("worktree-%s-%d", name, time.Now().Unix())
-	
-	// Create the worktree with -f flag in case it already exists
-	cmd := exec.Command("git", "worktree", "add", "-f", "-b", branchName, worktreePath)
-	cmd.Dir = repoPath
-	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to create worktree: %w\nOutput: %s", err, string(output))
+}
+
+// createFromTemplate creates a try from a template in the background.
+// Output of the template's post-create hook is only shown if it fails.
+func createFromTemplate(query string, template core.Template) tea.Cmd {
+	return func() tea.Msg {
+		var output bytes.Buffer
+		path, err := core.CreateDirectoryFromTemplate(query, template.Name, &output)
+		if err != nil && output.Len() > 0 {
+			err = fmt.Errorf("%w\n%s", err, strings.TrimSpace(output.String()))
+		}
+		return templateDoneMsg{path: path, err: err}
+	}
+}
+
+// cloneRepository clones in the background, sending progress updates on
+// updates and a cloneDoneMsg when git exits. The prompt accepts the same
+// options as `try clone`, e.g. "gh:user/repo --depth 1".
+func cloneRepository(ctx context.Context, input string, updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		defer close(updates)
+		
+		source, opts, err := core.ParseCloneArgs(strings.Fields(input))
+		if err != nil {
+			return cloneDoneMsg{err: err}
+		}
+		opts.Progress = true
+		
+		// Keep git's last message around to explain failures
+		lastMessage := ""
+		output := core.NewProgressWriter(func(progress core.GitProgress) {
+			if progress.Phase == "" {
+				lastMessage = progress.Line
+			}
+			// Only the latest update matters, so replace one the UI hasn't read yet
+			select {
+			case <-updates:
+			default:
+			}
+			updates <- cloneProgressMsg(progress)
+		})
+		
+		path, err := core.CloneRepository(ctx, source, opts, output)
+		if err != nil && ctx.Err() == nil && lastMessage != "" {
+			err = fmt.Errorf("%w\n%s", err, lastMessage)
+		}
+		return cloneDoneMsg{path: path, err: err}
+	}
+}
+
+// waitForCloneProgress delivers the next progress update of a running clone
+func waitForCloneProgress(updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// forkTry forks dir in the background. Output of the on-create hooks is
+// only shown if the fork fails.
+func forkTry(dir core.Directory, opts core.ForkOptions) tea.Cmd {
+	return func() tea.Msg {
+		var output bytes.Buffer
+		path, err := core.ForkDirectory(dir, opts, &output)
+		if err != nil && output.Len() > 0 {
+			err = fmt.Errorf("%w\n%s", err, strings.TrimSpace(output.String()))
+		}
+		return forkDoneMsg{path: path, err: err}
+	}
+}
+
+// createWorktreeFromPath creates a worktree on a new branch in the background
+func createWorktreeFromPath(repoPath, name string) tea.Cmd {
+	return func() tea.Msg {
+		if name == "" {
+			name = filepath.Base(repoPath) + "-worktree"
+		}
+		
+		// Create a unique branch name
+		branchName := fmt.Sprintf("worktree-%s-%d", strings.ReplaceAll(name, " ", "-"), time.Now().Unix())
+		
+		path, err := core.CreateWorktree(repoPath, name, branchName)
+		return worktreeDoneMsg{path: path, err: err}
 	}
-	
-	// Write path for cd
-	writeCdPath(worktreePath)
-	
-	return nil
 }
 
 func initializeGitRepository(path string) error {
@@ -4,69 +4,17 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/zengjie/try/core"
 )
 
-var (
-	// Main theme colors
-	primaryColor   = lipgloss.Color("#7C3AED") // Modern purple
-	secondaryColor = lipgloss.Color("#10B981") // Soft green
-	accentColor    = lipgloss.Color("#F59E0B") // Warm amber
-	dangerColor    = lipgloss.Color("#EF4444") // Soft red
-	
-	bgColor    = lipgloss.Color("#1E1E2E") // Dark background
-	fgColor    = lipgloss.Color("#CDD6F4") // Light foreground
-	dimColor   = lipgloss.Color("#6C7086") // Muted text
-	
-	// Title bar
-	titleStyle = lipgloss.NewStyle().
-			Background(primaryColor).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Bold(true).
-			Align(lipgloss.Center).
-			Padding(0, 1)
-	
-	// Search box (for when we're not filtering)
-	searchBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(0, 1)
-	
-	// Help view
-	helpViewStyle = lipgloss.NewStyle().
-			Padding(2, 4).
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor)
-	
-	// Status and help bars
-	statusBarStyle = lipgloss.NewStyle().
-			Background(bgColor).
-			Foreground(lipgloss.Color("#E5E7EB")).
-			Padding(0, 1)
-	
-	helpStyle = lipgloss.NewStyle().
-			Foreground(dimColor)
-	
-	// Error and warning styles
-	errorStyle = lipgloss.NewStyle().
-			Foreground(dangerColor).
-			Bold(true)
-	
-	deleteWarningStyle = lipgloss.NewStyle().
-			Foreground(dangerColor).
-			Bold(true)
-	
-	// Other styles
-	dimStyle = lipgloss.NewStyle().
-			Foreground(dimColor)
-	
-	highlightStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true)
-)
-
 func (m Model) View() string {
+	if m.quitting {
+		return ""
+	}
+
 	if m.err != nil {
 		return errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
 	}
@@ -97,18 +45,75 @@ func (m Model) View() string {
 
 	// Clone input (if active)
 	if m.cloning {
-		prompt := renderInputPrompt("📦 Clone Repository", "Enter Git URL:", m.cloneInput)
+		prompt := renderInputPrompt("📦 Clone Repository", "Enter Git URL and options (--depth 1, --branch b, ...):", m.cloneInput)
 		output.WriteString(prompt)
 		output.WriteString("\n")
 	}
 
-	// Delete confirmation (if active)
-	if m.deleting && m.deleteConfirm {
-		dir := m.filteredDirs[m.selectedForDelete]
-		deleteSection := deleteWarningStyle.Render(fmt.Sprintf("⚠️  Delete '%s'?", dir.Name)) + "\n" +
-			dimStyle.Render("Type 'yes' or directory name to confirm: ") + highlightStyle.Render(m.deleteInput) + "\n" +
-			helpStyle.Render("Press ESC to cancel")
-		output.WriteString(deleteSection)
+	// Background clone progress (if running)
+	if m.cloneRunning {
+		output.WriteString(renderCloneProgress(m.cloneSource, m.cloneProgress, m.width))
+		output.WriteString("\n")
+	}
+
+	if m.worktreeRunning {
+		output.WriteString(highlightStyle.Render("🌿 Creating worktree..."))
+		output.WriteString("\n")
+	}
+
+	if m.fetching {
+		output.WriteString(renderWorkInProgress("⬇️  Fetching", m.cancellingWork))
+		output.WriteString("\n")
+	}
+
+	if m.hooksRunning {
+		output.WriteString(renderWorkInProgress("🪝 Running hooks", m.cancellingWork))
+		output.WriteString("\n")
+	}
+
+	// Batch action picker, confirmation and tag prompt (if active)
+	if m.pickingAction {
+		output.WriteString(renderActionPicker(len(m.BatchTargets()), m.actionIndex))
+		output.WriteString("\n")
+	}
+
+	if m.batchAction != "" {
+		output.WriteString(renderBatchConfirm(m.batchAction, m.batchTargets, m.batchSummary, m.confirmInput, m.width))
+		output.WriteString("\n")
+	}
+
+	if m.runningBatch != "" {
+		output.WriteString(renderWorkInProgress(batchRunningLabels[m.runningBatch], m.cancellingWork))
+		output.WriteString("\n")
+	}
+
+	if m.forking {
+		output.WriteString(renderForkPrompt(m.forkDir, m.forkInput, m.forkModes, m.forkMode))
+		output.WriteString("\n")
+	}
+
+	if m.templateRunning {
+		output.WriteString(highlightStyle.Render("📄 Creating from template..."))
+		output.WriteString("\n")
+	}
+
+	if m.forkRunning {
+		output.WriteString(highlightStyle.Render("📋 Forking..."))
+		output.WriteString("\n")
+	}
+
+	if m.renaming {
+		output.WriteString(renderRenamePrompt(m.renameDir, m.renameInput))
+		output.WriteString("\n")
+	}
+
+	if m.tagging {
+		title := fmt.Sprintf("🏷  Tag %s", countTries(len(m.batchTargets)))
+		if len(m.batchTargets) == 1 {
+			title = fmt.Sprintf("🏷  Tag '%s'", m.batchTargets[0].Name)
+		}
+		prompt := renderInputPrompt(title, "Tags to add, -tag to remove:", m.tagInput)
+		output.WriteString(prompt)
 		output.WriteString("\n")
 	}
 
@@ -122,6 +127,20 @@ func (m Model) View() string {
 		}
 	}
 
+	// Template picker (if active)
+	if m.pickingTemplate {
+		output.WriteString(renderTemplatePicker(m.templateQuery, m.templates, m.templateIndex))
+		output.WriteString("\n")
+	}
+
+	// Opener picker (if active)
+	if m.pickingOpener {
+		if selected := m.GetSelected(); selected != nil {
+			output.WriteString(renderOpenerPicker(selected.Name, m.openers, m.openerIndex))
+			output.WriteString("\n")
+		}
+	}
+
 	// Creation preview (if in explicit creation mode)
 	if m.explicitCreating && m.query != "" {
 		creationPreview := renderCreationPreview(m.query)
@@ -136,29 +155,52 @@ func (m Model) View() string {
 		output.WriteString("\n")
 	} else {
 		// Add table header
-		header := renderTableHeader(m.width)
+		header := renderTableHeader(layoutColumns(m.width, m.columns, m.sortMode), m.sortMode)
 		output.WriteString(header)
 		output.WriteString("\n")
 		
 		// Get list view and strip any leading empty lines
 		listView := m.list.View()
 		listView = strings.TrimLeft(listView, "\n")
+		
+		// Prompts above take the list's room, the cursor row stays in view
+		if prompts := strings.Count(output.String(), "\n") - titleAndSearchLines - 1; prompts > 0 {
+			listView = clipLines(listView, max(m.list.Height()-prompts, 1), m.list.Cursor())
+		}
 		output.WriteString(listView)
 		output.WriteString("\n")
 	}
 
 	// Status bar
-	statusText := renderStatusBar(m.list.Index()+1, len(m.filteredDirs), m.query)
+	statusText := renderStatusBar(m.Position(), len(m.filteredDirs), m.query, m.OrderDescription(), len(m.marked), m.statusMessage)
 	output.WriteString(statusText)
 	output.WriteString("\n")
 
 	// Help bar
-	helpText := renderHelpBar()
+	helpText := renderHelpBar(m.keys)
 	output.WriteString(helpText)
 
 	return output.String()
 }
 
+// titleAndSearchLines is how many lines the title bar and search box take
+const titleAndSearchLines = 4
+
+// clipLines keeps at most n lines of s, dropping lines from the bottom, or
+// from the top when line keep would be dropped otherwise
+func clipLines(s string, n, keep int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	
+	start := 0
+	if keep >= n {
+		start = keep - n + 1
+	}
+	return strings.Join(lines[start:start+n], "\n")
+}
+
 func renderSearchBox(query string, isCreating bool, explicitCreating bool, width int) string {
 	if explicitCreating && query != "" {
 		// Explicit creation mode - show different style
@@ -187,6 +229,181 @@ func renderInputPrompt(title, prompt, input string) string {
 	return fmt.Sprintf("%s\n%s\n%s", titleLine, inputLine, helpLine)
 }
 
+func renderTemplatePicker(query string, templates []core.Template, selected int) string {
+	lines := []string{highlightStyle.Render(fmt.Sprintf("📁 Create '%s' from:", core.GenerateDatedName(query)))}
+	
+	options := []string{"Empty directory"}
+	for _, template := range templates {
+		options = append(options, template.Name)
+	}
+	
+	for i, option := range options {
+		if i == selected {
+			lines = append(lines, highlightStyle.Render("▶ "+option))
+		} else {
+			lines = append(lines, dimStyle.Render("  "+option))
+		}
+	}
+	
+	lines = append(lines, helpStyle.Render("↑/↓ to choose, Enter to create, ESC to cancel"))
+	return strings.Join(lines, "\n")
+}
+
+func renderOpenerPicker(name string, openers []string, selected int) string {
+	lines := []string{highlightStyle.Render(fmt.Sprintf("📝 Open '%s' with:", name))}
+	
+	for i, opener := range openers {
+		if i == selected {
+			lines = append(lines, highlightStyle.Render("▶ "+opener))
+		} else {
+			lines = append(lines, dimStyle.Render("  "+opener))
+		}
+	}
+	
+	lines = append(lines, helpStyle.Render("↑/↓ to choose, Enter to open, ESC to cancel"))
+	return strings.Join(lines, "\n")
+}
+
+// renderWorkInProgress shows what runs in the background and how to stop it
+func renderWorkInProgress(label string, cancelling bool) string {
+	if cancelling {
+		return highlightStyle.Render(label+"...") + " " + dimStyle.Render("cancelling")
+	}
+	return highlightStyle.Render(label+"...") + " " + helpStyle.Render("Press ESC to cancel")
+}
+
+func renderForkPrompt(dir core.Directory, input string, modes []core.ForkOptions, mode int) string {
+	titleLine := highlightStyle.Render(fmt.Sprintf("📋 Fork '%s'", dir.Name))
+	inputLine := dimStyle.Render("Name of the fork:") + " " + highlightStyle.Render(input)
+	
+	var how string
+	switch opts := modes[mode]; {
+	case opts.Worktree:
+		how = "new worktree on a branch from HEAD"
+	case opts.SkipArtifacts:
+		how = "copy without build artifacts"
+	default:
+		how = "copy"
+	}
+	previewLine := dimStyle.Render("Will create: ") + highlightStyle.Render(core.GenerateDatedName(input)) +
+		dimStyle.Render(" as ") + highlightStyle.Render(how)
+	
+	help := "Press Enter to fork, ESC to cancel"
+	if len(modes) > 1 {
+		help = "Press Tab to change how, Enter to fork, ESC to cancel"
+	}
+	return fmt.Sprintf("%s\n%s\n%s\n%s", titleLine, inputLine, previewLine, helpStyle.Render(help))
+}
+
+func renderRenamePrompt(dir core.Directory, input string) string {
+	titleLine := highlightStyle.Render(fmt.Sprintf("✏️  Rename '%s'", dir.Name))
+	inputLine := dimStyle.Render("New name (start with a date to change it):") + " " + highlightStyle.Render(input)
+	
+	var previewLine string
+	if target, err := core.RenameTarget(dir, input); err != nil {
+		previewLine = dimStyle.Render(err.Error())
+	} else {
+		previewLine = dimStyle.Render("Will rename to: ") + highlightStyle.Render(target)
+	}
+	
+	helpLine := helpStyle.Render("Press Enter to confirm, ESC to cancel")
+	return fmt.Sprintf("%s\n%s\n%s\n%s", titleLine, inputLine, previewLine, helpLine)
+}
+
+func renderActionPicker(targets int, selected int) string {
+	lines := []string{highlightStyle.Render(fmt.Sprintf("⚡ Do with %s:", countTries(targets)))}
+	
+	for i, action := range batchActions {
+		if i == selected {
+			lines = append(lines, highlightStyle.Render("▶ "+action.label))
+		} else {
+			lines = append(lines, dimStyle.Render("  "+action.label))
+		}
+	}
+	
+	lines = append(lines, helpStyle.Render("↑/↓ to choose, Enter to run, ESC to cancel"))
+	return strings.Join(lines, "\n")
+}
+
+// batchConfirmRows is how many tries a confirmation lists before
+// summarizing the rest
+const batchConfirmRows = 8
+
+func renderBatchConfirm(action batchAction, targets []core.Directory, summaries []core.TrySummary, input string, width int) string {
+	subject := countTries(len(targets))
+	if len(targets) == 1 {
+		subject = fmt.Sprintf("'%s'", targets[0].Name)
+	}
+	
+	var title string
+	switch action {
+	case batchDelete:
+		title = deleteWarningStyle.Render(fmt.Sprintf("⚠️  Delete %s?", subject))
+	case batchTrash:
+		title = highlightStyle.Render(fmt.Sprintf("🗑  Move %s to trash?", subject))
+	case batchArchive:
+		title = highlightStyle.Render(fmt.Sprintf("📦 Archive %s?", subject))
+	}
+	lines := []string{title}
+	
+	nameWidth := 0
+	for _, dir := range targets {
+		nameWidth = max(nameWidth, ansi.StringWidth(dir.Name))
+	}
+	// Leave room for the indent, the size and the dirty warning
+	nameWidth = max(min(nameWidth, width-34), minNameWidth)
+	
+	var total int64
+	dirty := 0
+	for i, dir := range targets {
+		if summaries != nil {
+			total += summaries[i].Size
+			if summaries[i].Dirty {
+				dirty++
+			}
+		}
+		if i >= batchConfirmRows {
+			continue
+		}
+		
+		name := fitWidth(truncateMiddle(dir.Name, nameWidth), nameWidth)
+		row := dimStyle.Render(fmt.Sprintf("  %s ", name))
+		switch {
+		case summaries == nil:
+			row += dimStyle.Render("measuring...")
+		case summaries[i].Dirty:
+			row += dimStyle.Render(fmt.Sprintf("%9s ", core.FormatSize(summaries[i].Size))) + deleteWarningStyle.Render("uncommitted changes")
+		default:
+			row += dimStyle.Render(fmt.Sprintf("%9s", core.FormatSize(summaries[i].Size)))
+		}
+		lines = append(lines, row)
+	}
+	if len(targets) > batchConfirmRows {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  ...and %d more", len(targets)-batchConfirmRows)))
+	}
+	
+	if summaries != nil && len(targets) > 1 {
+		totals := fmt.Sprintf("Total %s", core.FormatSize(total))
+		if dirty > 0 {
+			totals += fmt.Sprintf(", %s with uncommitted changes", countTries(dirty))
+		}
+		lines = append(lines, dimStyle.Render(totals))
+	}
+	
+	if action == batchDelete {
+		prompt := "Type 'yes' to confirm: "
+		if len(targets) == 1 {
+			prompt = "Type 'yes' or directory name to confirm: "
+		}
+		lines = append(lines,
+			dimStyle.Render(prompt)+highlightStyle.Render(input),
+			helpStyle.Render("Press ESC to cancel"))
+	} else {
+		lines = append(lines, dimStyle.Render("Press Y to confirm or N/ESC to cancel"))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func renderEmptyState(query string) string {
 	if query == "" {
 		return dimStyle.Render("  No directories yet. Start typing to create one!")
@@ -209,7 +426,11 @@ func renderCreationPreview(query string) string {
 	return fmt.Sprintf("%s\n%s\n%s\n%s", title, input, preview, help)
 }
 
-func renderStatusBar(current, total int, query string) string {
+func renderStatusBar(current, total int, query string, order string, marked int, message string) string {
+	if message != "" {
+		return statusBarStyle.Render(" " + message)
+	}
+	
 	if total == 0 {
 		return statusBarStyle.Render(" Ready to create new directory")
 	}
@@ -218,18 +439,59 @@ func renderStatusBar(current, total int, query string) string {
 	if query != "" {
 		status += fmt.Sprintf(" matching '%s'", query)
 	}
+	status += " • " + order
+	if marked > 0 {
+		status += fmt.Sprintf(" • %d marked", marked)
+	}
 	return statusBarStyle.Render(status)
 }
 
-func renderHelpBar() string {
+func renderCloneProgress(source string, progress core.GitProgress, width int) string {
+	titleLine := highlightStyle.Render(fmt.Sprintf("📦 Cloning %s", source))
+	
+	statusLine := dimStyle.Render("Starting git...")
+	if progress.Percent >= 0 {
+		barWidth := width - len(progress.Phase) - 12
+		if barWidth > 40 {
+			barWidth = 40
+		}
+		if barWidth < 10 {
+			barWidth = 10
+		}
+		filled := barWidth * progress.Percent / 100
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+		statusLine = highlightStyle.Render(bar) + dimStyle.Render(fmt.Sprintf(" %3d%% %s", progress.Percent, progress.Phase))
+	} else if progress.Phase != "" {
+		statusLine = dimStyle.Render(progress.Phase + "...")
+	} else if progress.Line != "" {
+		statusLine = dimStyle.Render(progress.Line)
+	}
+	
+	helpLine := helpStyle.Render("Press ESC to cancel")
+	return fmt.Sprintf("%s\n%s\n%s", titleLine, statusLine, helpLine)
+}
+
+func renderHelpBar(keys KeyMap) string {
 	shortcuts := []string{
-		"↑↓←→ Navigate",
-		"⏎ Select",
-		"^W Worktree",
-		"^G Clone",
-		"^D Delete",
-		"? Help",
-		"^C Quit",
+		shortKeyLabel(keys.Up) + shortKeyLabel(keys.Down) + shortKeyLabel(keys.PageUp) + shortKeyLabel(keys.PageDown) + " Navigate",
+	}
+	for _, item := range []struct {
+		binding key.Binding
+		label   string
+	}{
+		{keys.Select, "Select"},
+		{keys.Session, "Session"},
+		{keys.Worktree, "Worktree"},
+		{keys.Clone, "Clone"},
+		{keys.Mark, "Mark"},
+		{keys.Actions, "Actions"},
+		{keys.Delete, "Delete"},
+		{keys.Help, "Help"},
+		{keys.Quit, "Quit"},
+	} {
+		if item.binding.Enabled() {
+			shortcuts = append(shortcuts, shortKeyLabel(item.binding)+" "+item.label)
+		}
 	}
 	return helpStyle.Render(" " + strings.Join(shortcuts, " │ "))
 }
@@ -244,21 +506,8 @@ func renderTitleBar(width int) string {
 	return titleStyle.Render(paddedTitle)
 }
 
-func renderTableHeader(width int) string {
-	// Fixed column widths matching the delegate
-	// Using tab separation for better alignment
-	header := fmt.Sprintf("  %-*s %-*s %-*s", 
-		NameColumnWidth, "Name", 
-		TagsColumnWidth, "Tags", 
-		ModifiedColumnWidth, "Modified")
-	
-	// Style the header
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#7C3AED")).
-		Bold(true).
-		Underline(true)
-	
-	return headerStyle.Render(header)
+func renderTableHeader(layout columnLayout, sortMode core.SortMode) string {
+	return tableHeaderStyle.Render(layout.header(sortMode))
 }
 
 func (m Model) renderHelp() string {